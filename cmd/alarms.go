@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// alarmsCmd represents the alarms command
+var alarmsCmd = &cobra.Command{
+	Use:   "alarms",
+	Short: "List Eight Sleep routines and alarms",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetBool("verbose") {
+			logger.SetLevel(log.DebugLevel)
+		}
+
+		cli, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer cli.Stop()
+
+		routines, err := cli.GetRoutines(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if next := routines.State.NextAlarm.NextTimestamp; !next.IsZero() && !isStructuredOutput() {
+			logger.Info("Next alarm", "at", next.Local().Format(time.DateTime))
+		}
+
+		return printOutput(routines, alarmsTable(routines))
+	},
+}
+
+func alarmsTable(routines *eightsleep.Routines) *tableData {
+	tbl := &tableData{Headers: []string{"Routine", "Days", "Time", "Enabled", "Vibration", "Thermal"}}
+	for _, routine := range routines.Settings.Routines {
+		for _, alarm := range routine.Alarms {
+			tbl.add(routine.ID, strings.Join(routine.Days, ","), alarm.TimeWithOffset.Time,
+				fmt.Sprint(routine.Enabled && alarm.Enabled && !alarm.DisabledIndividually),
+				alarmVibration(alarm), alarmThermal(alarm))
+		}
+	}
+	for _, alarm := range routines.Settings.OneOffAlarms {
+		tbl.add("one-off", "", alarm.TimeWithOffset.Time, fmt.Sprint(alarm.Enabled),
+			alarmVibration(alarm), alarmThermal(alarm))
+	}
+	return tbl
+}
+
+func alarmVibration(alarm eightsleep.Alarm) string {
+	if !alarm.Settings.Vibration.Enabled {
+		return "off"
+	}
+	return fmt.Sprintf("%s %d%%", alarm.Settings.Vibration.Pattern, alarm.Settings.Vibration.PowerLevel)
+}
+
+func alarmThermal(alarm eightsleep.Alarm) string {
+	if !alarm.Settings.Thermal.Enabled {
+		return "off"
+	}
+	return fmt.Sprintf("level %d", alarm.Settings.Thermal.Level)
+}
+
+func init() {
+	rootCmd.AddCommand(alarmsCmd)
+}
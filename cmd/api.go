@@ -0,0 +1,220 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// apiCmd represents the api command
+var apiCmd = &cobra.Command{
+	Use:   "api [method] <path>",
+	Short: "Make an authenticated Eight Sleep API request",
+	Long: `Make an authenticated request to the Eight Sleep API and print the response.
+
+Useful to explore and script endpoints before clim8 supports them. The path
+includes the endpoint version and may use the {me} and {device} placeholders,
+which are replaced with your user ID and current device ID.
+
+Fields given with -f (string) or -F (typed: numbers, true, false and null) are
+sent as query parameters for GET and DELETE requests and as a JSON object body
+otherwise. Use --input to send a JSON body from a file, or - for stdin.
+
+The response is printed as JSON unless --output is given.`,
+	Example: `  clim8 api /v1/users/{me}/release-features
+  clim8 api GET /v1/devices/{device} --host client
+  clim8 api PUT /v1/users/{me}/level-suggestions-mode -F autoPilotEnabled=false
+  clim8 api POST /v2/users/{me}/routines --input routine.json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetBool("verbose") {
+			logger.SetLevel(log.DebugLevel)
+		}
+
+		method, path := http.MethodGet, args[0]
+		if len(args) == 2 {
+			method, path = strings.ToUpper(args[0]), args[1]
+		}
+
+		hostFlag, _ := cmd.Flags().GetString("host")
+		host := eightsleep.Host(strings.ToLower(hostFlag))
+		if host != eightsleep.AppHost && host != eightsleep.ClientHost {
+			return fmt.Errorf("invalid host '%s' (must be app or client)", hostFlag)
+		}
+
+		rawFields, _ := cmd.Flags().GetStringArray("raw-field")
+		typedFields, _ := cmd.Flags().GetStringArray("field")
+		fields, err := parseAPIFields(rawFields, typedFields)
+		if err != nil {
+			return err
+		}
+
+		var payload any
+		input, _ := cmd.Flags().GetString("input")
+		switch {
+		case input != "":
+			body, err := readAPIInput(input)
+			if err != nil {
+				return err
+			}
+			payload = body
+			// with an explicit body, fields are always query parameters
+			path = withQuery(path, fields)
+		case method == http.MethodGet || method == http.MethodDelete || method == http.MethodHead:
+			path = withQuery(path, fields)
+		case len(fields) > 0:
+			payload = fields
+		}
+
+		format := outputJSON
+		if cmd.Flags().Changed("output") {
+			if format, err = outputFormat(); err != nil {
+				return err
+			}
+		}
+
+		cli, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer cli.Stop()
+
+		var resp json.RawMessage
+		if err := cli.Do(cmd.Context(), method, host, path, payload, &resp); err != nil {
+			var apiErr *eightsleep.APIError
+			if errors.As(err, &apiErr) && len(apiErr.Body) > 0 {
+				fmt.Fprintln(os.Stderr, strings.TrimSpace(string(apiErr.Body)))
+			}
+			return err
+		}
+		if len(resp) == 0 {
+			return nil
+		}
+
+		var v any
+		if err := json.Unmarshal(resp, &v); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		return writeOutput(os.Stdout, format, v, nil)
+	},
+}
+
+// parseAPIFields parses key=value fields, raw fields are strings and typed fields are converted
+// to numbers, booleans or null where possible
+func parseAPIFields(raw, typed []string) (map[string]any, error) {
+	fields := make(map[string]any)
+	for _, f := range raw {
+		key, value, ok := strings.Cut(f, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid field '%s' (must be key=value)", f)
+		}
+		fields[key] = value
+	}
+	for _, f := range typed {
+		key, value, ok := strings.Cut(f, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid field '%s' (must be key=value)", f)
+		}
+		fields[key] = typedValue(value)
+	}
+	return fields, nil
+}
+
+func typedValue(value string) any {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	return value
+}
+
+// withQuery appends fields to the path as query parameters
+func withQuery(path string, fields map[string]any) string {
+	if len(fields) == 0 {
+		return path
+	}
+	query := url.Values{}
+	for key, value := range fields {
+		if value == nil {
+			query.Set(key, "")
+			continue
+		}
+		query.Set(key, fmt.Sprint(value))
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + query.Encode()
+}
+
+// readAPIInput reads a JSON request body from a file, or stdin when path is -
+func readAPIInput(path string) (json.RawMessage, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("input is not valid JSON")
+	}
+	return json.RawMessage(data), nil
+}
+
+func init() {
+	rootCmd.AddCommand(apiCmd)
+
+	apiCmd.Flags().StringArrayP("raw-field", "f", nil, "Add a string field in key=value format")
+	apiCmd.Flags().StringArrayP("field", "F", nil, "Add a typed field in key=value format")
+	apiCmd.Flags().String("host", string(eightsleep.AppHost), "API host to send the request to (app or client)")
+	apiCmd.Flags().String("input", "", "File to read the JSON request body from (- for stdin)")
+}
@@ -0,0 +1,146 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"sort"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// autopilotCmd represents the autopilot command
+var autopilotCmd = &cobra.Command{
+	Use:   "autopilot",
+	Short: "Show or toggle Eight Sleep Autopilot",
+	Args:  cobra.NoArgs,
+}
+
+// autopilotStatusCmd represents the autopilot status command
+var autopilotStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show Autopilot status and recent adjustments",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		return withAutopilotClient(cmd.Context(), func(cli *eightsleep.Client) error {
+			details, err := cli.GetAutopilotDetails(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if !isStructuredOutput() {
+				if details.Enabled {
+					logger.Info("Autopilot is ON")
+				} else {
+					logger.Info("Autopilot is OFF")
+				}
+			}
+
+			sort.Slice(details.Adjustments, func(i, j int) bool {
+				return details.Adjustments[i].Timestamp.After(details.Adjustments[j].Timestamp.Time)
+			})
+			if limit > 0 && len(details.Adjustments) > limit {
+				details.Adjustments = details.Adjustments[:limit]
+			}
+
+			if err := printOutput(details, autopilotTable(details, cli.Unit())); err != nil {
+				return err
+			}
+
+			return nil
+		})
+	},
+}
+
+// autopilotOnCmd represents the autopilot on command
+var autopilotOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Enable Autopilot",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAutopilotClient(cmd.Context(), func(cli *eightsleep.Client) error {
+			if err := cli.SetAutopilot(cmd.Context(), true); err != nil {
+				return err
+			}
+			logger.Info("Autopilot turned ON")
+			return nil
+		})
+	},
+}
+
+// autopilotOffCmd represents the autopilot off command
+var autopilotOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Disable Autopilot",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAutopilotClient(cmd.Context(), func(cli *eightsleep.Client) error {
+			if err := cli.SetAutopilot(cmd.Context(), false); err != nil {
+				return err
+			}
+			logger.Info("Autopilot turned OFF")
+			return nil
+		})
+	},
+}
+
+func autopilotTable(details *eightsleep.AutopilotDetails, unit eightsleep.UnitOfTemperature) *tableData {
+	tbl := &tableData{Headers: []string{"Time", "Stage", "From", "To", "Reason"}}
+	for _, adj := range details.Adjustments {
+		tbl.add(
+			adj.Timestamp.Local().Format("2006-01-02 15:04"),
+			adj.Stage,
+			eightsleep.FormatLevel(adj.PreviousLevel, unit),
+			eightsleep.FormatLevel(adj.NewLevel, unit),
+			adj.Reason,
+		)
+	}
+	return tbl
+}
+
+func withAutopilotClient(ctx context.Context, fn func(*eightsleep.Client) error) error {
+	if viper.GetBool("verbose") {
+		logger.SetLevel(log.DebugLevel)
+	}
+
+	cli, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cli.Stop()
+
+	return fn(cli)
+}
+
+func init() {
+	rootCmd.AddCommand(autopilotCmd)
+	autopilotCmd.AddCommand(autopilotStatusCmd)
+	autopilotCmd.AddCommand(autopilotOnCmd)
+	autopilotCmd.AddCommand(autopilotOffCmd)
+
+	autopilotStatusCmd.Flags().IntP("limit", "n", 10, "Maximum number of recent adjustments to show")
+}
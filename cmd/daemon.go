@@ -27,27 +27,73 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/blacktop/clim8/pkg/eightsleep"
 	"github.com/charmbracelet/log"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 // ScheduleItem represents a scheduled action
 type ScheduleItem struct {
-	Time        string `yaml:"time"`
-	Action      string `yaml:"action"`
-	Temperature string `yaml:"temperature,omitempty"`
+	Time        string `yaml:"time" json:"time"`
+	Action      string `yaml:"action" json:"action"`
+	Temperature string `yaml:"temperature,omitempty" json:"temperature,omitempty"`
+	// Days limits the item to the nights starting on days such as "mon", "weekdays" or
+	// "weekends", every night when empty
+	Days []string `yaml:"days,omitempty" json:"days,omitempty"`
+	// Precondition starts a temp action early enough to reach the temperature by Time
+	Precondition bool `yaml:"precondition,omitempty" json:"precondition,omitempty"`
+	// From, To, Duration and Step configure a ramp action, which gradually moves from one
+	// temperature to another starting at Time, changing the temperature every Step
+	From     string `yaml:"from,omitempty" json:"from,omitempty"`
+	To       string `yaml:"to,omitempty" json:"to,omitempty"`
+	Duration string `yaml:"duration,omitempty" json:"duration,omitempty"`
+	Step     string `yaml:"step,omitempty" json:"step,omitempty"`
+	// CatchUp is how missed occurrences are handled: latest, all or skip (default daemon.catch-up)
+	CatchUp string `yaml:"catch-up,omitempty" mapstructure:"catch-up" json:"catchUp,omitempty"`
+
+	// ramp is the step of a ramp action to execute
+	ramp *rampStep
 }
 
+const (
+	// maxPreconditionLead caps how early a preconditioned temp action may start
+	maxPreconditionLead = 3 * time.Hour
+	// maxSchedulerSleep caps how long the scheduler sleeps, so the device state is checked every
+	// minute and wall clock jumps are noticed
+	maxSchedulerSleep = time.Minute
+	// missedGrace is how late an item may run and still count as on time
+	missedGrace = time.Minute
+	// maxCatchUp is how far back missed items are caught up
+	maxCatchUp = 24 * time.Hour
+	// clockJumpThreshold is the difference between the wall clock and monotonic time elapsed
+	// between wakes that counts as a suspend or clock change
+	clockJumpThreshold = 30 * time.Second
+)
+
+// reloadConfigChanged is the reload reason when the config file was changed
+const reloadConfigChanged = "config file changed"
+
+// catch-up policies for missed items
+const (
+	catchUpLatest = "latest"
+	catchUpAll    = "all"
+	catchUpSkip   = "skip"
+)
+
 // ScheduleConfig represents the schedule configuration
 type ScheduleConfig struct {
 	Schedule []ScheduleItem `yaml:"schedule"`
+	// Schedules are named schedule sets, the name selects the nights the set runs on
+	Schedules map[string][]ScheduleItem `yaml:"schedules,omitempty"`
 }
 
 // daemonCmd represents the daemon command
@@ -67,6 +113,37 @@ schedule:
     temperature: "68"
   - time: "06:00"
     action: "off"
+    days: weekdays
+
+schedules:
+  weekends:
+    - time: "09:00"
+      action: "off"
+
+Items run every night unless limited with "days" (mon..sun, weekdays, weekends).
+Named schedule sets under "schedules" run on the nights their name selects, e.g.
+"weekdays", "weekends", "fri" or "sat,sun". Days select nights, named after the
+evening they start on, so "fri" includes 02:00 on Saturday. "weekdays" are the
+nights before workdays (Sunday to Thursday) and "weekends" the nights before
+Saturday and Sunday (Friday and Saturday), so the items above turn the pod off
+at 06:00 on workdays and at 09:00 on weekends.
+
+A "ramp" action moves gradually from one temperature to another:
+
+  - time: "05:30"
+    action: "ramp"
+    from: "65F"
+    to: "75F"
+    duration: "30m"
+    step: "5m"
+
+The schedule describes nights: items from the day boundary (--day-boundary,
+default 12:00) until the next day's boundary belong to the same night, so the
+01:00 and 06:00 items above belong to the night that started at 22:00.
+
+Set "precondition: true" on a temp action to reach the temperature by its time
+instead of starting to heat or cool at it. The lead time is estimated from the
+ramp rates learned from previous status polls.
 `,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -86,43 +163,180 @@ schedule:
 		}
 		defer removePidFile(pidFile)
 
-		// Parse schedule from config
-		var config ScheduleConfig
-		if err := viper.Unmarshal(&config); err != nil {
-			return fmt.Errorf("failed to parse schedule config: %w", err)
-		}
-
-		if len(config.Schedule) == 0 {
-			return fmt.Errorf("no schedule items found in config")
+		// Parse the settings and schedule from config
+		configFile := viper.ConfigFileUsed()
+		config, err := readDaemonConfig(configFile)
+		if err != nil {
+			return err
 		}
-
-		// Validate schedule items
-		for i, item := range config.Schedule {
-			if err := validateScheduleItem(item); err != nil {
-				return fmt.Errorf("invalid schedule item %d: %w", i, err)
-			}
+		settings, schedule, err := loadDaemonSettings(config)
+		if err != nil {
+			return err
 		}
+		loadedDaemonSettings.Store(settings)
 
-		logger.Info("Starting Eight Sleep scheduler daemon", "items", len(config.Schedule))
+		// Count the API requests and logins of the daemon's client in its metrics, and keep the
+		// systemd watchdog alive while they run
+		clientHooks = watchdogHooks(metrics.hooks())
 
 		// Set up signal handling for graceful shutdown
 		ctx, cancel := context.WithCancel(cmd.Context())
 		defer cancel()
+		defer resetDaemonClient()
+
+		// Evaluate the schedule in the configured timezone, or the pod's timezone by default
+		daemonLocation, err = resolveDaemonLocation(ctx)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Starting Eight Sleep scheduler daemon", "items", len(schedule), "timezone", daemonLocation)
 
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(sigChan)
+
+		go func() {
+			select {
+			case <-sigChan:
+				logger.Info("Received shutdown signal, stopping daemon...")
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		// Reload the schedule when the config file changes or on SIGHUP
+		reload := make(chan string, 1)
+		requestReload := func(reason string) {
+			select {
+			case reload <- reason:
+			default: // a reload is already pending
+			}
+		}
+		if configFile != "" {
+			if err := watchConfigFile(ctx, configFile, func() { requestReload(reloadConfigChanged) }); err != nil {
+				logger.Warn("Config file changes are not picked up, reload with SIGHUP", "err", err)
+			}
+		}
 
+		hupChan := make(chan os.Signal, 1)
+		signal.Notify(hupChan, syscall.SIGHUP)
+		defer signal.Stop(hupChan)
 		go func() {
-			<-sigChan
-			logger.Info("Received shutdown signal, stopping daemon...")
-			cancel()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-hupChan:
+					requestReload("SIGHUP")
+				}
+			}
 		}()
 
+		// Restore execution records, dry runs start over and are not persisted
+		state := &daemonState{Executions: make(map[string]executionRecord)}
+		if !settings.dryRun {
+			if state, err = loadDaemonState(); err != nil {
+				return err
+			}
+		}
+
+		// Serve the control API, its calls run on the scheduler's goroutine
+		control := make(chan controlCall)
+		stopControl, err := serveControl(ctx, control)
+		if err != nil {
+			return err
+		}
+		defer stopControl()
+
+		stopMetrics, err := serveMetricsListener(ctx)
+		if err != nil {
+			return err
+		}
+		defer stopMetrics()
+
 		// Run the scheduler
-		return runScheduler(ctx, config.Schedule)
+		return newScheduler(schedule, state).run(ctx, reload, control)
 	},
 }
 
+// sharedClient is the daemon's long-lived Eight Sleep client. It logs in on first use and then
+// stays logged in, the client refreshes its token and logs in again if the API rejects it.
+type sharedClient struct {
+	mu  sync.Mutex
+	cli *eightsleep.Client
+}
+
+var daemonClients sharedClient
+
+// daemonClient returns the daemon's shared client, logging in if there is none yet
+func daemonClient(ctx context.Context) (*eightsleep.Client, error) {
+	daemonClients.mu.Lock()
+	defer daemonClients.mu.Unlock()
+
+	if daemonClients.cli == nil {
+		cli, err := newClientFrom(ctx, currentDaemonSettings().config)
+		if err != nil {
+			return nil, err
+		}
+		daemonClients.cli = cli
+	}
+	return daemonClients.cli, nil
+}
+
+// refreshDaemonClient fetches the shared client's profile and devices again, so a long running
+// daemon picks up a changed side, unit or pod
+func refreshDaemonClient(ctx context.Context) {
+	daemonClients.mu.Lock()
+	cli := daemonClients.cli
+	daemonClients.mu.Unlock()
+	if cli == nil {
+		return
+	}
+	if err := cli.Refresh(ctx); err != nil {
+		logger.Warn("Failed to refresh account details", "err", err)
+		return
+	}
+	logger.Debug("Refreshed account details", "side", cli.Side(), "unit", cli.Unit())
+}
+
+// resetDaemonClient drops the shared client, the next use logs in again
+func resetDaemonClient() {
+	daemonClients.mu.Lock()
+	defer daemonClients.mu.Unlock()
+
+	if daemonClients.cli != nil {
+		daemonClients.cli.Stop()
+		daemonClients.cli = nil
+	}
+}
+
+// daemonLocation is the timezone the schedule is evaluated in
+var daemonLocation = time.Local
+
+// daemonNow returns the current time in the schedule's timezone
+func daemonNow() time.Time {
+	return time.Now().In(daemonLocation)
+}
+
+func resolveDaemonLocation(ctx context.Context) (*time.Location, error) {
+	if tz := currentDaemonSettings().config.GetString("timezone"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timezone %s: %w", tz, err)
+		}
+		return loc, nil
+	}
+
+	cli, err := daemonClient(ctx)
+	if err != nil {
+		logger.Warn("Failed to get pod timezone, using local time", "err", err)
+		return time.Local, nil
+	}
+
+	return cli.Location(), nil
+}
+
 func checkConfigSecurity() error {
 	configFile := viper.ConfigFileUsed()
 	if configFile == "" {
@@ -144,12 +358,12 @@ func checkConfigSecurity() error {
 }
 
 func createPidFile() (string, error) {
-	home, err := os.UserHomeDir()
+	dir, err := configDir()
 	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+		return "", err
 	}
 
-	pidFile := filepath.Join(home, ".config", "clim8", "daemon.pid")
+	pidFile := filepath.Join(dir, "daemon.pid")
 
 	// Check if PID file exists and process is running
 	if data, err := os.ReadFile(pidFile); err == nil {
@@ -176,29 +390,37 @@ func removePidFile(pidFile string) {
 	}
 }
 
+// validateTemperature checks the temperature syntax. Unitless temperatures are resolved against the
+// account's preferred unit when executed, so they are accepted if valid in the configured unit or,
+// when none is configured, in either unit.
 func validateTemperature(temp string) error {
-	if len(temp) < 2 {
-		return fmt.Errorf("temperature must be a number followed by F or C")
-	}
+	return validateTemperatureUnit(temp, viper.GetString("unit"))
+}
 
-	// Get the unit (last character)
-	unit := temp[len(temp)-1:]
-	if unit != "F" && unit != "C" {
-		return fmt.Errorf("temperature must end with F (Fahrenheit) or C (Celsius)")
+// validateTemperatureUnit checks the temperature syntax against the configured unit u
+func validateTemperatureUnit(temp, u string) error {
+	units := []eightsleep.UnitOfTemperature{eightsleep.Fahrenheit, eightsleep.Celsius}
+	if u != "" {
+		unit, err := eightsleep.ParseUnit(u)
+		if err != nil {
+			return err
+		}
+		units = []eightsleep.UnitOfTemperature{unit}
 	}
 
-	// Get the numeric part (everything except the last character)
-	numPart := temp[:len(temp)-1]
-	if _, err := strconv.ParseFloat(numPart, 64); err != nil {
-		return fmt.Errorf("temperature numeric part must be a valid number")
+	var err error
+	for _, unit := range units {
+		if _, err = eightsleep.ParseTemperature(temp, unit); err == nil {
+			return nil
+		}
 	}
-
-	return nil
+	return err
 }
 
-func validateScheduleItem(item ScheduleItem) error {
+// validateScheduleItem checks an item, unitless temperatures are checked against the configured unit
+func validateScheduleItem(item ScheduleItem, unit string) error {
 	// Validate time format (HH:MM)
-	if _, err := parseTime(item.Time); err != nil {
+	if _, err := parseTime(item.Time, time.Now()); err != nil {
 		return fmt.Errorf("invalid time format '%s': %w", item.Time, err)
 	}
 
@@ -210,18 +432,37 @@ func validateScheduleItem(item ScheduleItem) error {
 		if item.Temperature == "" {
 			return fmt.Errorf("temperature required for temp action")
 		}
-		// Validate temperature format (number + F/C)
-		if err := validateTemperature(item.Temperature); err != nil {
+		// Validate temperature format (number with optional F/C, or app scale)
+		if err := validateTemperatureUnit(item.Temperature, unit); err != nil {
 			return fmt.Errorf("invalid temperature '%s': %w", item.Temperature, err)
 		}
+	case "ramp":
+		if err := validateRamp(item, unit); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unknown action '%s'", item.Action)
 	}
 
+	if _, err := parseDays(item.Days); err != nil {
+		return fmt.Errorf("invalid days: %w", err)
+	}
+
+	if item.CatchUp != "" {
+		if err := validateCatchUp(item.CatchUp); err != nil {
+			return err
+		}
+	}
+
+	if item.Precondition && item.Action != "temp" {
+		return fmt.Errorf("precondition is only supported for temp actions")
+	}
+
 	return nil
 }
 
-func parseTime(timeStr string) (time.Time, error) {
+// parseTime returns the HH:MM time on the same day as now, in now's timezone
+func parseTime(timeStr string, now time.Time) (time.Time, error) {
 	parts := strings.Split(timeStr, ":")
 	if len(parts) != 2 {
 		return time.Time{}, fmt.Errorf("time must be in HH:MM format")
@@ -237,120 +478,672 @@ func parseTime(timeStr string) (time.Time, error) {
 		return time.Time{}, fmt.Errorf("invalid minute")
 	}
 
-	now := time.Now()
-	return time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location()), nil
+	t := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	// A time skipped when the clocks spring forward is due when they resume, 02:30 at 03:30
+	if t.Hour() != hour || t.Minute() != minute {
+		_, before := t.Zone()
+		_, after := t.Add(24 * time.Hour).Zone()
+		t = t.Add(time.Duration(after-before) * time.Second)
+	}
+	return t, nil
 }
 
 func logUpcomingSchedule(schedule []ScheduleItem) {
 	logger.Info("Loaded schedule:")
 	for i, item := range schedule {
-		if item.Temperature != "" {
-			logger.Info(fmt.Sprintf("  %d. %s - %s", i+1, item.Time, item.Action),
-				"temperature", item.Temperature)
-		} else {
-			logger.Info(fmt.Sprintf("  %d. %s - %s", i+1, item.Time, item.Action))
+		logger.Info(fmt.Sprintf("  %d. %s - %s", i+1, item.Time, item.Action), scheduleItemDetails(item)...)
+	}
+}
+
+// loadSchedule parses and validates the schedule from the config in v
+func loadSchedule(v *viper.Viper) ([]ScheduleItem, error) {
+	var config ScheduleConfig
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule config: %w", err)
+	}
+
+	schedule, err := config.Items()
+	if err != nil {
+		return nil, err
+	}
+	if len(schedule) == 0 {
+		return nil, fmt.Errorf("no schedule items found in config")
+	}
+
+	// Validate schedule items
+	for i, item := range schedule {
+		if err := validateScheduleItem(item, v.GetString("unit")); err != nil {
+			return nil, fmt.Errorf("invalid schedule item %d: %w", i, err)
 		}
 	}
+
+	return schedule, nil
+}
+
+// scheduler runs the daemon's schedule. The schedule and execution tracking are only accessed
+// from the goroutine calling run, reloads are requested over a channel.
+type scheduler struct {
+	schedule []ScheduleItem
+	// state records executions to prevent duplicates and when items last ran, it is persisted
+	// unless persist is false
+	state   *daemonState
+	persist bool
+	// lastNight is the date of the night of the last wake
+	lastNight string
+	// lastWake is the time of the last wake including its monotonic clock reading
+	lastWake time.Time
+	// pending are items that failed and are retried within missedGrace
+	pending []occurrence
+	// watchdog is how often systemd expects a keep-alive from the scheduler, zero when disabled
+	watchdog time.Duration
+	// action executes an item, executeAction unless replaced in tests
+	action func(ctx context.Context, item ScheduleItem) error
+}
+
+func newScheduler(schedule []ScheduleItem, state *daemonState) *scheduler {
+	return &scheduler{
+		schedule: schedule,
+		state:    state,
+		persist:  !currentDaemonSettings().dryRun,
+		watchdog: watchdogInterval(),
+		action:   executeAction,
+	}
 }
 
-func runScheduler(ctx context.Context, schedule []ScheduleItem) error {
-	// Track executed items to prevent duplicates
-	executed := make(map[string]bool)
-	var lastDay int
+// run executes the schedule until ctx is done, reloading it whenever a reason is received on
+// reload and running control API calls received on control. It sleeps until the next item is due,
+// waking at least every minute to check the device state and notice wall clock jumps.
+//
+// Under systemd it reports readiness before the first wake, as a wake may take several API
+// requests, and its status after every wake. It sends the watchdog keep-alive from this loop and
+// after every API request the loop makes, so a scheduler stuck on an API call is restarted.
+func (s *scheduler) run(ctx context.Context, reload <-chan string, control <-chan controlCall) error {
+	ctx = withWatchdog(ctx)
 
 	// Log upcoming schedule
-	logUpcomingSchedule(schedule)
+	logUpcomingSchedule(s.schedule)
 
-	ticker := time.NewTicker(time.Minute)
-	defer ticker.Stop()
+	// wake right away, then whenever the next item is due
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
+	notifyService("READY=1")
 	for {
 		select {
 		case <-ctx.Done():
+			notifyService("STOPPING=1")
 			logger.Info("Scheduler stopped")
 			return nil
-		case <-ticker.C:
-			now := time.Now()
-
-			// Reset execution tracking at start of new day
-			if now.Day() != lastDay {
-				executed = make(map[string]bool)
-				lastDay = now.Day()
-				logger.Info("New day started, reset execution tracking", "date", now.Format("2006-01-02"))
+		case reason := <-reload:
+			if err := s.reload(reason); err != nil {
+				logger.Error("Failed to reload schedule, keeping current schedule", "err", err)
 			}
+		case call := <-control:
+			call.fn(ctx, s)
+			close(call.done)
+		case <-timer.C:
+			s.wake(ctx)
+		}
 
-			// Check and sync device state before processing schedule
-			if viper.GetBool("daemon.sync-state") {
-				if err := checkAndSyncDeviceState(ctx, schedule); err != nil {
-					logger.Warn("Failed to check/sync device state", "err", err)
-				}
+		now := daemonNow()
+		if s.watchdog > 0 {
+			notifyService("WATCHDOG=1", "STATUS="+s.statusLine(now))
+		} else {
+			notifyService("STATUS=" + s.statusLine(now))
+		}
+		timer.Reset(time.Until(s.nextWake(now)))
+	}
+}
+
+// statusLine summarizes what the scheduler does next, reported to systemd
+func (s *scheduler) statusLine(now time.Time) string {
+	switch {
+	case s.state.Paused && !s.state.PausedUntil.IsZero():
+		return "Paused until " + s.state.PausedUntil.Format(time.DateTime)
+	case s.state.Paused:
+		return "Paused"
+	}
+	for _, event := range s.upcoming(now, len(s.schedule)+1) {
+		if event.Result != "" {
+			continue
+		}
+		line := fmt.Sprintf("Next: %s %s", event.Due.Format("Mon 15:04"), event.Item.Action)
+		if event.Item.Temperature != "" {
+			line += " " + event.Item.Temperature
+		}
+		return line
+	}
+	return "No upcoming actions"
+}
+
+// wake runs everything that became due since the last wake
+func (s *scheduler) wake(ctx context.Context) {
+	wall := time.Now()
+	now := wall.In(daemonLocation)
+
+	// The timer runs on the monotonic clock, so a difference between the wall clock and monotonic
+	// time elapsed means the system was suspended or its clock was changed
+	jumped := false
+	if !s.lastWake.IsZero() {
+		jump := wall.Round(0).Sub(s.lastWake.Round(0)) - wall.Sub(s.lastWake)
+		if jump > clockJumpThreshold || jump < -clockJumpThreshold {
+			logger.Warn("Wall clock jumped, the system was suspended or its clock changed", "by", jump.Round(time.Second))
+			jumped = true
+		}
+	}
+	firstWake := s.lastWake.IsZero()
+	s.lastWake = wall
+
+	switch {
+	case s.state.LastRun.IsZero():
+		// on first start, run items due within the grace period
+		s.state.LastRun = now.Add(-missedGrace)
+	case now.Before(s.state.LastRun):
+		// items between now and the last run already ran or were skipped
+		logger.Warn("Wall clock moved backwards, not running items again", "from", s.state.LastRun.Format(time.DateTime), "to", now.Format(time.DateTime))
+		s.state.LastRun = now
+		jumped = true
+	case firstWake && now.Sub(s.state.LastRun) > missedGrace:
+		logger.Info("Resuming schedule from last run", "last_run", s.state.LastRun.Format(time.DateTime))
+	}
+
+	if s.state.Paused && !s.state.PausedUntil.IsZero() && !now.Before(s.state.PausedUntil) {
+		logger.Info("Pause ended, resuming schedule")
+		s.state.Paused, s.state.PausedUntil = false, time.Time{}
+	}
+
+	// Forget old executions at the start of a new night
+	if night := nightOf(now); night != s.lastNight {
+		s.pruneExecuted(now)
+		if s.lastNight != "" {
+			refreshDaemonClient(ctx)
+		}
+		s.lastNight = night
+		logger.Info("New night started", "night", night, "since", nightStart(now).Format(time.DateTime))
+
+		checkDaemonSubscription(ctx)
+	}
+
+	// Check and sync device state before processing schedule. After a suspend or clock change the
+	// pod may have missed anything, so it is synced even if periodic syncing is disabled.
+	if (currentDaemonSettings().syncState || jumped) && !s.state.Paused {
+		if err := checkAndSyncDeviceState(ctx, s.schedule); err != nil {
+			logger.Warn("Failed to check/sync device state", "err", err)
+		}
+	}
+
+	// Poll the pod to learn ramp rates and time preconditioned actions
+	var ramp *rampSnapshot
+	if usesPrecondition(s.schedule) {
+		ramp = observeRamp(ctx)
+	}
+
+	s.runDue(ctx, now, ramp)
+	s.state.LastRun = now
+	s.saveState()
+}
+
+// done reports whether the execution with key already ran or was skipped
+func (s *scheduler) done(key string) bool {
+	record, ok := s.state.Executions[key]
+	return ok && record.done()
+}
+
+// record stores the result of an execution
+func (s *scheduler) record(key string, o occurrence, result string, err error) {
+	record := executionRecord{
+		Time:   o.item.Time,
+		Action: o.item.Action,
+		Due:    o.due,
+		RanAt:  daemonNow(),
+		Result: result,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	s.state.Executions[key] = record
+}
+
+// saveState persists the execution records
+func (s *scheduler) saveState() {
+	if !s.persist {
+		return
+	}
+	if err := s.state.save(); err != nil {
+		logger.Warn("Failed to save daemon state", "err", err)
+	}
+}
+
+// occurrence is an item due at a specific time
+type occurrence struct {
+	item ScheduleItem
+	due  time.Time
+	// retry is set when the occurrence failed before
+	retry bool
+}
+
+// runDue runs the items that became due since the last run. Items due more than missedGrace ago
+// were missed, e.g. while the system was suspended, and are handled by their catch-up policy
+// before the items that are due now, so the most recent item decides the pod's state.
+//
+// Items that failed are retried on every wake until they succeed, a later item ran or their night
+// ended. Retries more than missedGrace late are handled like missed items.
+func (s *scheduler) runDue(ctx context.Context, now time.Time, ramp *rampSnapshot) {
+	due := s.retries(now)
+	s.pending = nil
+
+	for _, item := range s.schedule {
+		if item.Action == "ramp" {
+			if !s.state.Paused {
+				s.processRamp(ctx, item, now)
 			}
+			continue
+		}
+		for _, t := range occurrences(item, s.state.LastRun, now) {
+			metrics.actionScheduled(item.Action)
+			due = append(due, occurrence{item: item, due: t})
+		}
+		if item.Precondition && !s.state.Paused {
+			s.precondition(ctx, item, now, ramp)
+		}
+	}
 
-			if err := processSchedule(ctx, schedule, executed); err != nil {
-				logger.Error("Error processing schedule", "err", err)
+	// Items due while paused are skipped for good, they do not run on resume
+	if s.state.Paused {
+		for _, o := range due {
+			if key := executionKey(o.due, o.item); !s.done(key) {
+				logger.Info("Skipping scheduled action, daemon is paused", "due", o.item.Time, "action", o.item.Action)
+				s.record(key, o, resultSkipped, nil)
 			}
 		}
+		return
+	}
+
+	var missed, onTime []occurrence
+	for _, o := range due {
+		switch {
+		case s.done(executionKey(o.due, o.item)):
+		case now.Sub(o.due) > missedGrace:
+			missed = append(missed, o)
+		default:
+			onTime = append(onTime, o)
+		}
+	}
+
+	s.catchUp(ctx, missed, now)
+	for _, o := range onTime {
+		if err := s.execute(ctx, o, "Executing scheduled action"); err != nil {
+			s.pending = append(s.pending, o)
+		}
 	}
 }
 
-func processSchedule(ctx context.Context, schedule []ScheduleItem, executed map[string]bool) error {
-	now := time.Now()
+// retries returns the failed occurrences to run again: those of the current night that no later
+// occurrence has run after
+func (s *scheduler) retries(now time.Time) []occurrence {
+	var latest time.Time
+	for _, record := range s.state.Executions {
+		if record.Result == resultOK && record.Due.After(latest) {
+			latest = record.Due
+		}
+	}
 
-	// Find items that should run now (within the last minute)
-	for _, item := range schedule {
-		scheduledTime, err := parseTime(item.Time)
-		if err != nil {
+	var retry []occurrence
+	for _, o := range s.pending {
+		if nightOf(o.due) != nightOf(now) || o.due.Before(latest) {
+			logger.Warn("Giving up on failed action", "due", o.due.Format(time.DateTime), "action", o.item.Action)
 			continue
 		}
+		o.retry = true
+		retry = append(retry, o)
+	}
+	return retry
+}
 
-		// Create unique key for this execution
-		execKey := fmt.Sprintf("%s-%s-%s",
-			now.Format("2006-01-02"),
-			item.Time,
-			item.Action)
+// catchUp applies the catch-up policy of missed items: "all" runs every missed occurrence,
+// "latest" runs only the most recent of the missed "latest" items and "skip" runs none. Items
+// that fail are retried on the next wake, a retry is not skipped by the "skip" policy since the
+// item was not missed.
+func (s *scheduler) catchUp(ctx context.Context, missed []occurrence, now time.Time) {
+	sort.Slice(missed, func(i, j int) bool { return missed[i].due.Before(missed[j].due) })
+
+	latest := -1
+	for i, o := range missed {
+		if itemCatchUp(o.item) == catchUpLatest {
+			latest = i
+		}
+	}
 
-		// Skip if already executed today
-		if executed[execKey] {
+	for i, o := range missed {
+		policy := itemCatchUp(o.item)
+		if policy == catchUpSkip && !o.retry || policy == catchUpLatest && i != latest {
+			logger.Warn("Skipping missed action",
+				"due", o.due.Format(time.DateTime),
+				"action", o.item.Action,
+				"catch-up", policy)
+			s.record(executionKey(o.due, o.item), o, resultSkipped, nil)
 			continue
 		}
+		logger.Warn("Catching up missed action",
+			"due", o.due.Format(time.DateTime),
+			"late", now.Sub(o.due).Round(time.Second),
+			"catch-up", policy)
+		if err := s.execute(ctx, o, "Executing missed action"); err != nil {
+			s.pending = append(s.pending, o)
+		}
+	}
+}
 
-		// If scheduled time is today and within the last minute
-		if scheduledTime.Day() == now.Day() &&
-			scheduledTime.Month() == now.Month() &&
-			scheduledTime.Year() == now.Year() {
-
-			timeDiff := now.Sub(scheduledTime)
-			if timeDiff >= 0 && timeDiff < time.Minute {
-				if item.Temperature != "" {
-					logger.Info("Executing scheduled action",
-						"time", item.Time,
-						"action", item.Action,
-						"temperature", item.Temperature)
-				} else {
-					logger.Info("Executing scheduled action",
-						"time", item.Time,
-						"action", item.Action)
-				}
+// precondition starts the next occurrence of a preconditioned item early by the time the pod
+// needs to reach its temperature
+func (s *scheduler) precondition(ctx context.Context, item ScheduleItem, now time.Time, ramp *rampSnapshot) {
+	due, ok := nextOccurrence(item, now)
+	if !ok || due.Sub(now) > maxPreconditionLead || s.done(executionKey(due, item)) {
+		return
+	}
+	lead := ramp.lead(item)
+	if lead <= 0 || now.Before(due.Add(-lead)) {
+		return
+	}
+	logger.Info("Preconditioning ahead of scheduled action",
+		"due", item.Time,
+		"temperature", item.Temperature,
+		"lead", due.Sub(now).Round(time.Minute))
+	s.execute(ctx, occurrence{item: item, due: due}, "")
+}
 
-				if err := executeAction(ctx, item); err != nil {
-					logger.Error("Failed to execute action",
-						"action", item.Action,
-						"err", err)
-				} else {
-					// Mark as executed
-					executed[execKey] = true
-				}
+// execute runs an occurrence and records its result
+func (s *scheduler) execute(ctx context.Context, o occurrence, msg string, step ...int) error {
+	if msg != "" {
+		logger.Info(msg, append([]any{"due", o.item.Time, "action", o.item.Action}, scheduleItemDetails(o.item)...)...)
+	}
+	key := executionKey(o.due, o.item, step...)
+	err := s.action(ctx, o.item)
+	metrics.actionExecuted(o.item.Action, err)
+	if err != nil {
+		logger.Error("Failed to execute action",
+			"action", o.item.Action,
+			"err", err)
+		s.record(key, o, resultFailed, err)
+		return err
+	}
+	s.record(key, o, resultOK, nil)
+	return nil
+}
+
+// pruneExecuted forgets executions older than maxCatchUp plus a day, those can no longer be due
+func (s *scheduler) pruneExecuted(now time.Time) {
+	cutoff := now.Add(-maxCatchUp).AddDate(0, 0, -1)
+	for key, record := range s.state.Executions {
+		if record.Due.Before(cutoff) {
+			delete(s.state.Executions, key)
+		}
+	}
+}
+
+// nextWake returns when the scheduler must wake next: when the next item or ramp step is due,
+// but no later than maxSchedulerSleep from now
+func (s *scheduler) nextWake(now time.Time) time.Time {
+	next := now.Add(maxSchedulerSleep)
+	// keep the systemd watchdog fed
+	if s.watchdog > 0 && s.watchdog/2 < maxSchedulerSleep {
+		next = now.Add(s.watchdog / 2)
+	}
+	if s.state.Paused && !s.state.PausedUntil.IsZero() && s.state.PausedUntil.Before(next) {
+		next = s.state.PausedUntil
+	}
+	for _, item := range s.schedule {
+		if t, ok := nextOccurrence(item, now); ok && t.Before(next) {
+			next = t
+		}
+		if item.Action != "ramp" {
+			continue
+		}
+		// the next step of a ramp in progress
+		start, ok := rampStart(item, now)
+		if !ok || !now.Before(item.rampEnd(start)) {
+			continue
+		}
+		if _, step, err := item.rampTiming(); err == nil {
+			t := start.Add((now.Sub(start)/step + 1) * step)
+			if end := item.rampEnd(start); t.After(end) {
+				t = end
 			}
+			if t.Before(next) {
+				next = t
+			}
+		}
+	}
+	return next
+}
+
+// occurrences returns when the item is due after from and up to and including to, looking back
+// at most maxCatchUp. Days select the night an occurrence belongs to.
+func occurrences(item ScheduleItem, from, to time.Time) []time.Time {
+	if earliest := to.Add(-maxCatchUp); from.Before(earliest) {
+		from = earliest
+	}
+	var due []time.Time
+	for day := from.AddDate(0, 0, -1); !day.After(to.AddDate(0, 0, 1)); day = day.AddDate(0, 0, 1) {
+		t, err := parseTime(item.Time, day)
+		if err != nil {
+			return nil
+		}
+		if t.After(from) && !t.After(to) && item.runsOn(nightStart(t).Weekday()) {
+			due = append(due, t)
+		}
+	}
+	return due
+}
+
+// nextOccurrence returns when the item is next due after now, on a night its days select
+func nextOccurrence(item ScheduleItem, now time.Time) (time.Time, bool) {
+	for day := 0; day <= 7; day++ {
+		t, err := parseTime(item.Time, now.AddDate(0, 0, day))
+		if err != nil {
+			return time.Time{}, false
+		}
+		if t.After(now) && item.runsOn(nightStart(t).Weekday()) {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// itemCatchUp returns the catch-up policy of an item, or the daemon's default
+func itemCatchUp(item ScheduleItem) string {
+	if item.CatchUp != "" {
+		return item.CatchUp
+	}
+	return currentDaemonSettings().catchUp
+}
+
+// validateCatchUp checks a catch-up policy
+func validateCatchUp(policy string) error {
+	switch policy {
+	case catchUpLatest, catchUpAll, catchUpSkip:
+		return nil
+	}
+	return fmt.Errorf("invalid catch-up policy '%s' (must be latest, all or skip)", policy)
+}
+
+// reload re-reads and validates the config, swapping in its settings and schedule only if they
+// are valid. Execution tracking is kept for items that are still scheduled, so they do not run
+// twice tonight.
+func (s *scheduler) reload(reason string) error {
+	logger.Info("Reloading schedule", "reason", reason)
+
+	prev := currentDaemonSettings()
+	config, err := readDaemonConfig(prev.config.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+	settings, schedule, err := loadDaemonSettings(config)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	// dry runs neither read nor write the state, so they cannot be switched on the fly
+	if settings.dryRun != prev.dryRun {
+		logger.Warn("Changing daemon.dry-run requires a restart", "dry_run", prev.dryRun)
+		settings.dryRun = prev.dryRun
+	}
+	loadedDaemonSettings.Store(settings)
+	settings.logChanges(prev)
+	if settings.dayBoundary != prev.dayBoundary {
+		s.state.rekey()
+		s.saveState()
+	}
+
+	// Log in again with changed credentials
+	if settings.clientChanged(prev) {
+		logger.Info("Credentials changed, logging in again")
+		resetDaemonClient()
+	}
+
+	added, removed := diffSchedules(s.schedule, schedule)
+	if len(added) == 0 && len(removed) == 0 {
+		logger.Info("Schedule unchanged")
+		return nil
+	}
+	for _, item := range removed {
+		logger.Info(fmt.Sprintf("Schedule item removed: %s - %s", item.Time, item.Action), scheduleItemDetails(item)...)
+	}
+	for _, item := range added {
+		logger.Info(fmt.Sprintf("Schedule item added: %s - %s", item.Time, item.Action), scheduleItemDetails(item)...)
+	}
+
+	// Forget executions of items that are no longer scheduled
+	for key := range s.state.Executions {
+		if !executedKeyScheduled(key, schedule) {
+			delete(s.state.Executions, key)
 		}
 	}
 
+	s.schedule = schedule
+	s.saveState()
+	logger.Info("Schedule reloaded", "items", len(schedule), "added", len(added), "removed", len(removed))
 	return nil
 }
 
+// diffSchedules returns the items only in next and the items only in prev
+func diffSchedules(prev, next []ScheduleItem) (added, removed []ScheduleItem) {
+	count := make(map[string]int)
+	for _, item := range prev {
+		count[scheduleItemID(item)]++
+	}
+	for _, item := range next {
+		id := scheduleItemID(item)
+		if count[id] > 0 {
+			count[id]--
+			continue
+		}
+		added = append(added, item)
+	}
+	for _, item := range prev {
+		id := scheduleItemID(item)
+		if count[id] > 0 {
+			count[id]--
+			removed = append(removed, item)
+		}
+	}
+	return added, removed
+}
+
+// scheduleItemID identifies an item by all of its settings
+func scheduleItemID(item ScheduleItem) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%t|%s|%s|%s|%s|%s", item.Time, item.Action, item.Temperature,
+		strings.Join(item.Days, ","), item.Precondition, item.From, item.To, item.Duration, item.Step, item.CatchUp)
+}
+
+// scheduleItemDetails returns the item's settings besides time and action as log key/value pairs
+func scheduleItemDetails(item ScheduleItem) []any {
+	var kv []any
+	if item.Temperature != "" {
+		kv = append(kv, "temperature", item.Temperature)
+	}
+	if item.Action == "ramp" {
+		kv = append(kv, "from", item.From, "to", item.To, "duration", item.Duration)
+	}
+	if len(item.Days) > 0 {
+		kv = append(kv, "days", strings.Join(item.Days, ","))
+	}
+	if item.Precondition {
+		kv = append(kv, "precondition", true)
+	}
+	if item.CatchUp != "" {
+		kv = append(kv, "catch-up", item.CatchUp)
+	}
+	return kv
+}
+
+// executionKey identifies the execution of an item due at due in its night, with an optional
+// ramp step. Only the item's time and action are part of the key, so an item that already ran
+// tonight is not run again when just its other settings, e.g. the temperature, are changed; state
+// synchronization applies them when the item is the one in effect.
+func executionKey(due time.Time, item ScheduleItem, step ...int) string {
+	key := fmt.Sprintf("%s-%s-%s", nightOf(due), item.Time, item.Action)
+	for _, n := range step {
+		key += fmt.Sprintf("-%d", n)
+	}
+	return key
+}
+
+// executedKeyScheduled reports whether an execution key belongs to an item in the schedule
+func executedKeyScheduled(key string, schedule []ScheduleItem) bool {
+	_, rest, ok := strings.Cut(key[min(len(key), len("2006-01-02")):], "-")
+	if !ok {
+		return false
+	}
+	for _, item := range schedule {
+		id := item.Time + "-" + item.Action
+		if rest == id || strings.HasPrefix(rest, id+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// processRamp executes the current step of a ramp action that is in progress. Steps are derived
+// from the clock, so a daemon restarted mid-ramp continues at the step that applies now.
+func (s *scheduler) processRamp(ctx context.Context, item ScheduleItem, now time.Time) {
+	start, ok := rampStart(item, now)
+	if !ok || !now.Before(item.rampEnd(start).Add(time.Minute)) {
+		return
+	}
+
+	step, err := item.rampStepAt(start, now)
+	if err != nil {
+		logger.Error("Invalid ramp", "time", item.Time, "err", err)
+		return
+	}
+
+	// a ramp skipped as a whole is recorded without a step
+	if s.done(executionKey(start, item)) || s.done(executionKey(start, item, step.Index)) {
+		return
+	}
+
+	metrics.actionScheduled(item.Action)
+	logger.Info("Executing scheduled ramp step",
+		"time", item.Time,
+		"step", fmt.Sprintf("%d/%d", step.Index, step.Count),
+		"from", item.From,
+		"to", item.To)
+
+	item.ramp = &step
+	s.execute(ctx, occurrence{item: item, due: start}, "", step.Index)
+}
+
 func executeAction(ctx context.Context, item ScheduleItem) error {
 	// Check if this is a dry run
-	if viper.GetBool("daemon.dry-run") {
-		if item.Temperature != "" {
+	if currentDaemonSettings().dryRun {
+		if item.ramp != nil {
+			logger.Info("DRY RUN - Would execute action",
+				"action", item.Action,
+				"step", fmt.Sprintf("%d/%d", item.ramp.Index, item.ramp.Count),
+				"from", item.From,
+				"to", item.To)
+		} else if item.Temperature != "" {
 			logger.Info("DRY RUN - Would execute action",
 				"action", item.Action,
 				"temperature", item.Temperature)
@@ -361,19 +1154,10 @@ func executeAction(ctx context.Context, item ScheduleItem) error {
 		return nil
 	}
 
-	// Create Eight Sleep client
-	cli, err := eightsleep.NewClient(
-		viper.GetString("email"),
-		viper.GetString("password"),
-		viper.GetString("daemon.timezone"),
-	)
+	// Use the daemon's shared Eight Sleep client
+	cli, err := daemonClient(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
-	}
-	defer cli.Stop()
-
-	if err := cli.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start client: %w", err)
+		return err
 	}
 
 	switch item.Action {
@@ -400,6 +1184,25 @@ func executeAction(ctx context.Context, item ScheduleItem) error {
 		}
 		logger.Info("Temperature set", "temp", item.Temperature)
 
+	case "ramp":
+		if item.ramp == nil {
+			return fmt.Errorf("ramp action without a step")
+		}
+		level, err := item.ramp.level(cli.Unit())
+		if err != nil {
+			return err
+		}
+
+		if err := cli.TurnOn(ctx); err != nil {
+			return fmt.Errorf("failed to turn on before setting temperature: %w", err)
+		}
+
+		if err := cli.SetHeatingLevel(ctx, level); err != nil {
+			return fmt.Errorf("failed to set temperature: %w", err)
+		}
+		logger.Info("Temperature set", "level", eightsleep.FormatLevel(level, cli.Unit()),
+			"step", fmt.Sprintf("%d/%d", item.ramp.Index, item.ramp.Count))
+
 	default:
 		return fmt.Errorf("unknown action: %s", item.Action)
 	}
@@ -407,36 +1210,110 @@ func executeAction(ctx context.Context, item ScheduleItem) error {
 	return nil
 }
 
-// getExpectedState determines what the device state should be based on the schedule and current time
+// usesPrecondition reports whether any schedule item is preconditioned
+func usesPrecondition(schedule []ScheduleItem) bool {
+	for _, item := range schedule {
+		if item.Precondition {
+			return true
+		}
+	}
+	return false
+}
+
+// rampSnapshot is the pod status seen on a scheduler tick, used to time preconditioned actions
+type rampSnapshot struct {
+	cli    *eightsleep.Client
+	status *eightsleep.PodStatus
+}
+
+// observeRamp polls the pod status so ramp rates keep being learned, returning nil on failure
+func observeRamp(ctx context.Context) *rampSnapshot {
+	cli, status, err := pollDevice(ctx)
+	if err != nil {
+		logger.Warn("Failed to get status for ramp observation", "err", err)
+		return nil
+	}
+	return &rampSnapshot{cli: cli, status: status}
+}
+
+// pollDevice polls the pod with the daemon's shared client, learning ramp rates and updating the
+// metrics
+func pollDevice(ctx context.Context) (*eightsleep.Client, *eightsleep.PodStatus, error) {
+	cli, err := daemonClient(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	status, err := cli.Status(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get current device state: %w", err)
+	}
+	saveRampRates(cli)
+	metrics.polled(status)
+	return cli, status, nil
+}
+
+// lead returns how long before its time a temp action must start to reach its temperature in
+// time, or zero when no ramp rate has been observed yet
+func (r *rampSnapshot) lead(item ScheduleItem) time.Duration {
+	if r == nil {
+		return 0
+	}
+	target, err := eightsleep.ParseTemperature(item.Temperature, r.cli.Unit())
+	if err != nil {
+		return 0
+	}
+	current := r.status.Side(r.cli.Side()).Level
+	lead, ok := r.cli.EstimateRamp(current, target)
+	if !ok {
+		logger.Debug("No ramp rate observed yet, starting at the scheduled time", "time", item.Time)
+		return 0
+	}
+	return min(lead, maxPreconditionLead)
+}
+
+// checkDaemonSubscription warns in the daemon log when the membership is about to lapse
+func checkDaemonSubscription(ctx context.Context) {
+	cli, err := daemonClient(ctx)
+	if err != nil {
+		logger.Warn("Failed to create client for subscription check", "err", err)
+		return
+	}
+
+	checkSubscription(ctx, cli)
+}
+
+// getExpectedState determines what the device state should be based on the schedule and current
+// time: the state left by the most recent item of the current night. Nights span midnight, so at
+// 00:30 the 22:15 item of the previous evening still applies.
 func getExpectedState(schedule []ScheduleItem, now time.Time) (*ScheduleItem, error) {
 	if len(schedule) == 0 {
 		return nil, nil
 	}
 
-	// Sort schedule items by time to find the most recent one
-	var sortedItems []ScheduleItem
-	sortedItems = append(sortedItems, schedule...)
+	start := nightStart(now)
 
-	// Find the most recent schedule item that should have executed today
+	// Find the most recent schedule item that should have executed tonight
 	var mostRecentItem *ScheduleItem
 	var mostRecentTime time.Time
 
-	for _, item := range sortedItems {
-		scheduledTime, err := parseTime(item.Time)
-		if err != nil {
+	for _, item := range schedule {
+		scheduledTime, err := item.nightTime(start)
+		if err != nil || !scheduledTime.Before(now) || !item.runsOn(start.Weekday()) {
 			continue
 		}
 
-		// Check if this item should have executed today and is in the past
-		if scheduledTime.Day() == now.Day() &&
-			scheduledTime.Month() == now.Month() &&
-			scheduledTime.Year() == now.Year() &&
-			scheduledTime.Before(now) {
-
-			if mostRecentItem == nil || scheduledTime.After(mostRecentTime) {
-				mostRecentItem = &item
-				mostRecentTime = scheduledTime
+		// A ramp is expected at the level of its current step
+		if item.Action == "ramp" {
+			step, err := item.rampStepAt(scheduledTime, now)
+			if err != nil {
+				continue
 			}
+			item.ramp = &step
+		}
+
+		if mostRecentItem == nil || scheduledTime.After(mostRecentTime) {
+			mostRecentItem = &item
+			mostRecentTime = scheduledTime
 		}
 	}
 
@@ -446,44 +1323,29 @@ func getExpectedState(schedule []ScheduleItem, now time.Time) (*ScheduleItem, er
 // checkAndSyncDeviceState checks if the device is in the expected state and corrects it if needed
 func checkAndSyncDeviceState(ctx context.Context, schedule []ScheduleItem) error {
 	// Skip if dry run mode
-	if viper.GetBool("daemon.dry-run") {
+	if currentDaemonSettings().dryRun {
 		return nil
 	}
 
-	now := time.Now()
+	now := daemonNow()
 	expectedState, err := getExpectedState(schedule, now)
 	if err != nil {
 		return fmt.Errorf("failed to determine expected state: %w", err)
 	}
 
-	// If no expected state (e.g., before first scheduled item today), do nothing
+	// If no expected state (e.g., before the first scheduled item tonight), do nothing
 	if expectedState == nil {
 		return nil
 	}
 
-	// Create client to check current state
-	cli, err := eightsleep.NewClient(
-		viper.GetString("email"),
-		viper.GetString("password"),
-		viper.GetString("daemon.timezone"),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
-	}
-	defer cli.Stop()
-
-	if err := cli.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start client: %w", err)
-	}
-
-	// Get current device state
-	currentState, err := cli.GetTemperatureState(ctx)
+	// Get current device state with the daemon's shared client
+	cli, status, err := pollDevice(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get current temperature state: %w", err)
+		return err
 	}
 
-	// Check if device state matches expected state
-	stateMatches, err := deviceStateMatches(currentState, expectedState)
+	// Check if the user's side matches the expected state
+	stateMatches, err := scheduleExpectedState(*expectedState).matches(status.Side(cli.Side()), status.Unit)
 	if err != nil {
 		return fmt.Errorf("failed to check device state: %w", err)
 	}
@@ -497,6 +1359,7 @@ func checkAndSyncDeviceState(ctx context.Context, schedule []ScheduleItem) error
 		if err := executeAction(ctx, *expectedState); err != nil {
 			return fmt.Errorf("failed to sync device state: %w", err)
 		}
+		metrics.syncCorrected()
 
 		logger.Info("Device state synced successfully")
 	}
@@ -504,109 +1367,33 @@ func checkAndSyncDeviceState(ctx context.Context, schedule []ScheduleItem) error
 	return nil
 }
 
-// deviceStateMatches checks if the current device state matches the expected schedule item
-func deviceStateMatches(currentState *eightsleep.TemperatureState, expectedItem *ScheduleItem) (bool, error) {
-	if len(currentState.Devices) == 0 {
-		return false, fmt.Errorf("no devices found in current state")
-	}
-
-	device := currentState.Devices[0] // Assuming single device
-
-	switch expectedItem.Action {
-	case "off":
-		return device.CurrentState.Type == "off", nil
-
-	case "on":
-		return device.CurrentState.Type != "off", nil
-
-	case "temp":
-		if device.CurrentState.Type == "off" {
-			return false, nil
-		}
-
-		// Parse expected temperature
-		if expectedItem.Temperature == "" {
-			return false, fmt.Errorf("temperature action requires temperature value")
-		}
-
-		var unit eightsleep.UnitOfTemperature
-		switch {
-		case strings.HasSuffix(expectedItem.Temperature, "C"):
-			unit = eightsleep.Celsius
-		case strings.HasSuffix(expectedItem.Temperature, "F"):
-			unit = eightsleep.Fahrenheit
-		default:
-			return false, fmt.Errorf("invalid temperature format: %s", expectedItem.Temperature)
-		}
-
-		tempStr := strings.Trim(expectedItem.Temperature, "CF")
-		expectedTemp, err := strconv.Atoi(tempStr)
-		if err != nil {
-			return false, fmt.Errorf("invalid temperature value: %s", tempStr)
-		}
-
-		// Convert expected temperature to heating level for comparison
-		expectedLevel := tempToHeatingLevel(expectedTemp, unit)
-
-		// Allow some tolerance for heating level comparison (±2 levels)
-		tolerance := 2
-		return abs(device.CurrentLevel-expectedLevel) <= tolerance, nil
+func init() {
+	rootCmd.AddCommand(daemonCmd)
 
-	default:
-		return false, fmt.Errorf("unknown action: %s", expectedItem.Action)
+	// Add daemon-specific flags
+	daemonCmd.Flags().Bool("dry-run", false, "Show what would be executed without actually running actions")
+	daemonCmd.Flags().Bool("sync-state", true, "Check and sync device state with the schedule every minute (always done after a suspend or clock change)")
+	daemonCmd.Flags().String("catch-up", catchUpLatest, "How to handle items missed while suspended: latest, all or skip")
+	daemonCmd.PersistentFlags().String("listen", "", "Control API address, a unix socket path or loopback host:port (default ~/.config/clim8/daemon.sock, \"off\" to disable)")
+	daemonCmd.Flags().String("day-boundary", defaultDayBoundary, "Time (HH:MM) one night of the schedule ends and the next begins")
+	daemonCmd.Flags().String("metrics-listen", "", "Serve Prometheus metrics on this host:port, e.g. 127.0.0.1:9788 (also served by the control API)")
+	daemonFlags = map[string]*pflag.Flag{
+		"daemon.dry-run":        daemonCmd.Flags().Lookup("dry-run"),
+		"daemon.sync-state":     daemonCmd.Flags().Lookup("sync-state"),
+		"daemon.catch-up":       daemonCmd.Flags().Lookup("catch-up"),
+		"daemon.listen":         daemonCmd.PersistentFlags().Lookup("listen"),
+		"daemon.metrics-listen": daemonCmd.Flags().Lookup("metrics-listen"),
+		"daemon.day-boundary":   daemonCmd.Flags().Lookup("day-boundary"),
 	}
+	bindDaemonFlags(viper.GetViper())
 }
 
-// Helper function to convert temperature to heating level (moved from eightsleep package)
-func tempToHeatingLevel(deg int, unit eightsleep.UnitOfTemperature) int {
-	// This is a simplified version - you may want to import the actual function
-	// from the eightsleep package or expose it publicly
-	var rawToF = map[int]int{
-		-100: 55, -99: 56, -97: 57, -95: 58, -94: 59, -92: 60, -90: 61, -86: 62,
-		-81: 63, -77: 64, -72: 65, -68: 66, -63: 67, -58: 68, -54: 69, -49: 70,
-		-44: 71, -40: 72, -35: 73, -31: 74, -26: 75, -21: 76, -18: 77, -17: 77,
-		-12: 78, -7: 79, -3: 80, 1: 81, 4: 82, 7: 83, 10: 84, 14: 85, 16: 86,
-		17: 86, 20: 87, 23: 88, 26: 89, 29: 90, 32: 91, 35: 92, 38: 93, 41: 94,
-		44: 95, 48: 96, 51: 97, 54: 98, 57: 99, 60: 100, 63: 101, 66: 102,
-		69: 103, 72: 104, 75: 105, 78: 106, 80: 107, 81: 107, 85: 108, 88: 109,
-		92: 110, 100: 111,
-	}
-	var rawToC = map[int]int{
-		-100: 13, -97: 14, -94: 15, -91: 16, -83: 17, -75: 18, -67: 19, -58: 20,
-		-50: 21, -42: 22, -33: 23, -25: 24, -17: 25, -8: 26, 0: 27, 6: 28,
-		11: 29, 17: 30, 22: 31, 28: 32, 33: 33, 39: 34, 44: 35, 50: 36,
-		56: 37, 61: 38, 67: 39, 72: 40, 78: 41, 83: 42, 89: 43, 100: 44,
-	}
-
-	m := rawToF
-	if unit == eightsleep.Celsius {
-		m = rawToC
-	}
-	closestKey := 0
-	minDiff := 1 << 31
-	for k, v := range m {
-		if d := abs(v - deg); d < minDiff {
-			minDiff, closestKey = d, k
-		}
-	}
-	return closestKey
-}
+// daemonFlags are the daemon's flags by the config key they are bound to
+var daemonFlags map[string]*pflag.Flag
 
-func abs(n int) int {
-	if n < 0 {
-		return -n
+// bindDaemonFlags binds the daemon's flags to their config keys in v
+func bindDaemonFlags(v *viper.Viper) {
+	for key, flag := range daemonFlags {
+		v.BindPFlag(key, flag)
 	}
-	return n
-}
-
-func init() {
-	rootCmd.AddCommand(daemonCmd)
-
-	// Add daemon-specific flags
-	daemonCmd.Flags().Bool("dry-run", false, "Show what would be executed without actually running actions")
-	daemonCmd.Flags().String("timezone", "America/New_York", "Timezone for schedule execution")
-	daemonCmd.Flags().Bool("sync-state", true, "Check and sync device state with schedule after system wake")
-	viper.BindPFlag("daemon.dry-run", daemonCmd.Flags().Lookup("dry-run"))
-	viper.BindPFlag("daemon.timezone", daemonCmd.Flags().Lookup("timezone"))
-	viper.BindPFlag("daemon.sync-state", daemonCmd.Flags().Lookup("sync-state"))
 }
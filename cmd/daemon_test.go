@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiffSchedules(t *testing.T) {
+	on := ScheduleItem{Time: "22:00", Action: "on"}
+	temp := ScheduleItem{Time: "22:15", Action: "temp", Temperature: "68F"}
+	warmer := ScheduleItem{Time: "22:15", Action: "temp", Temperature: "70F"}
+	off := ScheduleItem{Time: "06:00", Action: "off"}
+	offWeekdays := ScheduleItem{Time: "06:00", Action: "off", Days: []string{"weekdays"}}
+
+	tests := []struct {
+		name          string
+		prev, next    []ScheduleItem
+		added, remove []ScheduleItem
+	}{
+		{name: "unchanged", prev: []ScheduleItem{on, temp, off}, next: []ScheduleItem{on, temp, off}},
+		{name: "reordered", prev: []ScheduleItem{on, temp, off}, next: []ScheduleItem{off, on, temp}},
+		{name: "added", prev: []ScheduleItem{on}, next: []ScheduleItem{on, off}, added: []ScheduleItem{off}},
+		{name: "removed", prev: []ScheduleItem{on, off}, next: []ScheduleItem{on}, remove: []ScheduleItem{off}},
+		{name: "setting changed", prev: []ScheduleItem{on, temp}, next: []ScheduleItem{on, warmer}, added: []ScheduleItem{warmer}, remove: []ScheduleItem{temp}},
+		{name: "days changed", prev: []ScheduleItem{off}, next: []ScheduleItem{offWeekdays}, added: []ScheduleItem{offWeekdays}, remove: []ScheduleItem{off}},
+		{name: "duplicate added", prev: []ScheduleItem{on}, next: []ScheduleItem{on, on}, added: []ScheduleItem{on}},
+		{name: "duplicate removed", prev: []ScheduleItem{on, on}, next: []ScheduleItem{on}, remove: []ScheduleItem{on}},
+		{name: "from empty", next: []ScheduleItem{on}, added: []ScheduleItem{on}},
+	}
+	for _, tt := range tests {
+		added, removed := diffSchedules(tt.prev, tt.next)
+		if !sameItems(added, tt.added) || !sameItems(removed, tt.remove) {
+			t.Errorf("%s: diffSchedules() = added %+v, removed %+v, want added %+v, removed %+v", tt.name, added, removed, tt.added, tt.remove)
+		}
+	}
+}
+
+func sameItems(a, b []ScheduleItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if scheduleItemID(a[i]) != scheduleItemID(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLoadDaemonSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    daemonSettings
+		items   int
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			config: "schedule:\n  - time: \"22:00\"\n    action: \"on\"\n",
+			want:   daemonSettings{syncState: true, catchUp: catchUpLatest, dayBoundary: defaultDayBoundary},
+			items:  1,
+		},
+		{
+			name:   "daemon settings",
+			config: "daemon:\n  sync-state: false\n  catch-up: all\n  day-boundary: \"15:00\"\n  dry-run: true\nschedule:\n  - time: \"22:00\"\n    action: \"on\"\n  - time: \"06:00\"\n    action: \"off\"\n",
+			want:   daemonSettings{dryRun: true, catchUp: catchUpAll, dayBoundary: "15:00"},
+			items:  2,
+		},
+		{name: "invalid catch-up", config: "daemon:\n  catch-up: never\nschedule:\n  - time: \"22:00\"\n    action: \"on\"\n", wantErr: true},
+		{name: "invalid day boundary", config: "daemon:\n  day-boundary: noon\nschedule:\n  - time: \"22:00\"\n    action: \"on\"\n", wantErr: true},
+		{name: "invalid item", config: "schedule:\n  - time: \"25:00\"\n    action: \"on\"\n", wantErr: true},
+		{name: "no schedule", config: "email: a@example.com\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		file := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(file, []byte(tt.config), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config, err := readDaemonConfig(file)
+		if err != nil {
+			t.Fatalf("%s: readDaemonConfig() error = %v", tt.name, err)
+		}
+		settings, schedule, err := loadDaemonSettings(config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: loadDaemonSettings() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if settings.dryRun != tt.want.dryRun || settings.syncState != tt.want.syncState ||
+			settings.catchUp != tt.want.catchUp || settings.dayBoundary != tt.want.dayBoundary || len(schedule) != tt.items {
+			t.Errorf("%s: loadDaemonSettings() = %+v with %d items, want %+v with %d items", tt.name, *settings, len(schedule), tt.want, tt.items)
+		}
+	}
+}
+
+// date returns a time on a day of March 2024 in UTC, March 1st 2024 is a Friday
+func date(day, hour, minute int) time.Time {
+	return time.Date(2024, time.March, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestOccurrences(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     ScheduleItem
+		from, to time.Time
+		want     []time.Time
+	}{
+		{name: "due", item: ScheduleItem{Time: "22:00"}, from: date(1, 21, 0), to: date(1, 23, 0), want: []time.Time{date(1, 22, 0)}},
+		{name: "from is exclusive", item: ScheduleItem{Time: "22:00"}, from: date(1, 22, 0), to: date(1, 23, 0)},
+		{name: "to is inclusive", item: ScheduleItem{Time: "22:00"}, from: date(1, 21, 0), to: date(1, 22, 0), want: []time.Time{date(1, 22, 0)}},
+		{name: "not yet due", item: ScheduleItem{Time: "22:00"}, from: date(1, 20, 0), to: date(1, 21, 0)},
+		{name: "after midnight", item: ScheduleItem{Time: "01:00"}, from: date(1, 23, 0), to: date(2, 2, 0), want: []time.Time{date(2, 1, 0)}},
+		{name: "capped at maxCatchUp", item: ScheduleItem{Time: "22:00"}, from: date(1, 12, 0), to: date(3, 23, 0), want: []time.Time{date(3, 22, 0)}},
+		{name: "days", item: ScheduleItem{Time: "22:00", Days: []string{"sat"}}, from: date(1, 12, 0), to: date(2, 23, 0), want: []time.Time{date(2, 22, 0)}},
+		{name: "days select the night", item: ScheduleItem{Time: "02:00", Days: []string{"fri"}}, from: date(1, 12, 0), to: date(2, 12, 0), want: []time.Time{date(2, 2, 0)}},
+		{name: "days end with the night", item: ScheduleItem{Time: "07:00", Days: []string{"fri"}}, from: date(1, 12, 0), to: date(2, 12, 0), want: []time.Time{date(2, 7, 0)}},
+		{name: "previous night", item: ScheduleItem{Time: "02:00", Days: []string{"fri"}}, from: date(1, 0, 0), to: date(1, 12, 0)},
+		{name: "weekdays on a saturday morning", item: ScheduleItem{Time: "06:00", Days: []string{"weekdays"}}, from: date(1, 12, 0), to: date(2, 12, 0)},
+		{name: "weekdays on a monday morning", item: ScheduleItem{Time: "06:00", Days: []string{"weekdays"}}, from: date(3, 12, 0), to: date(4, 12, 0), want: []time.Time{date(4, 6, 0)}},
+		{name: "weekdays on a friday morning", item: ScheduleItem{Time: "06:00", Days: []string{"weekdays"}}, from: date(1, 0, 0), to: date(1, 12, 0), want: []time.Time{date(1, 6, 0)}},
+		{name: "weekdays on a sunday evening", item: ScheduleItem{Time: "22:00", Days: []string{"weekdays"}}, from: date(3, 12, 0), to: date(3, 23, 0), want: []time.Time{date(3, 22, 0)}},
+		{name: "weekends on a saturday morning", item: ScheduleItem{Time: "09:00", Days: []string{"weekends"}}, from: date(1, 12, 0), to: date(2, 12, 0), want: []time.Time{date(2, 9, 0)}},
+		{name: "weekends on a monday morning", item: ScheduleItem{Time: "09:00", Days: []string{"weekends"}}, from: date(3, 12, 0), to: date(4, 12, 0)},
+		{name: "invalid time", item: ScheduleItem{Time: "late"}, from: date(1, 12, 0), to: date(2, 23, 0)},
+	}
+	for _, tt := range tests {
+		if got := occurrences(tt.item, tt.from, tt.to); !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+			t.Errorf("%s: occurrences() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	tests := []struct {
+		name   string
+		item   ScheduleItem
+		now    time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "later today", item: ScheduleItem{Time: "22:00"}, now: date(1, 21, 0), want: date(1, 22, 0), wantOK: true},
+		{name: "due now", item: ScheduleItem{Time: "22:00"}, now: date(1, 22, 0), want: date(2, 22, 0), wantOK: true},
+		{name: "after midnight", item: ScheduleItem{Time: "01:00"}, now: date(1, 23, 0), want: date(2, 1, 0), wantOK: true},
+		{name: "days", item: ScheduleItem{Time: "22:00", Days: []string{"mon"}}, now: date(1, 21, 0), want: date(4, 22, 0), wantOK: true},
+		{name: "days select the night", item: ScheduleItem{Time: "02:00", Days: []string{"fri"}}, now: date(1, 1, 0), want: date(2, 2, 0), wantOK: true},
+		{name: "invalid time", item: ScheduleItem{Time: "late"}, now: date(1, 21, 0)},
+	}
+	for _, tt := range tests {
+		got, ok := nextOccurrence(tt.item, tt.now)
+		if ok != tt.wantOK || !got.Equal(tt.want) {
+			t.Errorf("%s: nextOccurrence() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// testScheduler returns a scheduler whose actions are recorded in ran instead of executed. Actions
+// at the times in fail return an error.
+func testScheduler(schedule []ScheduleItem, ran *[]string, fail ...string) *scheduler {
+	return &scheduler{
+		schedule: schedule,
+		state:    &daemonState{Executions: make(map[string]executionRecord)},
+		action: func(ctx context.Context, item ScheduleItem) error {
+			*ran = append(*ran, item.Time)
+			if slices.Contains(fail, item.Time) {
+				return errors.New("failed")
+			}
+			return nil
+		},
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	on := ScheduleItem{Time: "22:00", Action: "on"}
+	temp := ScheduleItem{Time: "22:15", Action: "temp", Temperature: "68F"}
+	withCatchUp := func(item ScheduleItem, policy string) ScheduleItem {
+		item.CatchUp = policy
+		return item
+	}
+
+	tests := []struct {
+		name    string
+		missed  []occurrence
+		fail    []string
+		ran     []string
+		results map[string]string
+		pending int
+	}{
+		{
+			name:    "latest",
+			missed:  []occurrence{{item: temp, due: date(1, 22, 15)}, {item: on, due: date(1, 22, 0)}},
+			ran:     []string{"22:15"},
+			results: map[string]string{"22:00": resultSkipped, "22:15": resultOK},
+		},
+		{
+			name:    "all",
+			missed:  []occurrence{{item: withCatchUp(temp, catchUpAll), due: date(1, 22, 15)}, {item: withCatchUp(on, catchUpAll), due: date(1, 22, 0)}},
+			ran:     []string{"22:00", "22:15"},
+			results: map[string]string{"22:00": resultOK, "22:15": resultOK},
+		},
+		{
+			name:    "skip",
+			missed:  []occurrence{{item: withCatchUp(on, catchUpSkip), due: date(1, 22, 0)}},
+			results: map[string]string{"22:00": resultSkipped},
+		},
+		{
+			name:    "skip does not apply to retries",
+			missed:  []occurrence{{item: withCatchUp(on, catchUpSkip), due: date(1, 22, 0), retry: true}},
+			ran:     []string{"22:00"},
+			results: map[string]string{"22:00": resultOK},
+		},
+		{
+			name:    "failed",
+			missed:  []occurrence{{item: on, due: date(1, 22, 0)}},
+			fail:    []string{"22:00"},
+			ran:     []string{"22:00"},
+			results: map[string]string{"22:00": resultFailed},
+			pending: 1,
+		},
+	}
+	for _, tt := range tests {
+		var ran []string
+		s := testScheduler(nil, &ran, tt.fail...)
+		s.catchUp(context.Background(), tt.missed, date(1, 23, 0))
+		if !slices.Equal(ran, tt.ran) {
+			t.Errorf("%s: ran %v, want %v", tt.name, ran, tt.ran)
+		}
+		for _, o := range tt.missed {
+			if got := s.state.Executions[executionKey(o.due, o.item)].Result; got != tt.results[o.item.Time] {
+				t.Errorf("%s: result of %s = %q, want %q", tt.name, o.item.Time, got, tt.results[o.item.Time])
+			}
+		}
+		if len(s.pending) != tt.pending {
+			t.Errorf("%s: %d pending, want %d", tt.name, len(s.pending), tt.pending)
+		}
+	}
+}
+
+func TestRunDueRetries(t *testing.T) {
+	on := ScheduleItem{Time: "22:00", Action: "on"}
+	temp := ScheduleItem{Time: "22:15", Action: "temp", Temperature: "68F"}
+	ctx := context.Background()
+
+	// a failed item is retried, also once it is late
+	var ran []string
+	s := testScheduler([]ScheduleItem{on, temp}, &ran, "22:00")
+	s.state.LastRun = date(1, 21, 59)
+	s.runDue(ctx, date(1, 22, 0), nil)
+	s.state.LastRun = date(1, 22, 0)
+	s.runDue(ctx, date(1, 22, 1), nil)
+	s.state.LastRun = date(1, 22, 1)
+	s.action = testScheduler(nil, &ran).action
+	s.runDue(ctx, date(1, 22, 5), nil)
+	if want := []string{"22:00", "22:00", "22:00"}; !slices.Equal(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+	if got := s.state.Executions[executionKey(date(1, 22, 0), on)].Result; got != resultOK || len(s.pending) != 0 {
+		t.Errorf("result = %q with %d pending, want %q with none", got, len(s.pending), resultOK)
+	}
+
+	// a retry is dropped once a later item ran
+	ran = nil
+	s = testScheduler([]ScheduleItem{on, temp}, &ran, "22:00")
+	s.state.LastRun = date(1, 21, 59)
+	s.runDue(ctx, date(1, 22, 0), nil)
+	s.state.LastRun = date(1, 22, 0)
+	s.runDue(ctx, date(1, 22, 15), nil)
+	if want := []string{"22:00", "22:00", "22:15"}; !slices.Equal(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+	s.state.LastRun = date(1, 22, 15)
+	s.runDue(ctx, date(1, 22, 16), nil)
+	if len(ran) != 3 || len(s.pending) != 0 {
+		t.Errorf("ran %v with %d pending after a later item ran, want no retry", ran, len(s.pending))
+	}
+
+	// a retry is dropped once its night ended
+	ran = nil
+	s = testScheduler([]ScheduleItem{on}, &ran, "22:00")
+	s.state.LastRun = date(1, 21, 59)
+	s.runDue(ctx, date(1, 22, 0), nil)
+	s.state.LastRun = date(2, 11, 59)
+	s.runDue(ctx, date(2, 12, 0), nil)
+	if len(ran) != 1 || len(s.pending) != 0 {
+		t.Errorf("ran %v with %d pending in the next night, want no retry", ran, len(s.pending))
+	}
+}
@@ -0,0 +1,521 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+)
+
+const (
+	// defaultUpcomingEvents is how many upcoming events the control API lists by default
+	defaultUpcomingEvents = 5
+	// defaultRecentResults is how many execution results the control API lists by default
+	defaultRecentResults = 10
+)
+
+// controlCall runs fn on the scheduler's goroutine, done is closed once it returned
+type controlCall struct {
+	fn   func(ctx context.Context, s *scheduler)
+	done chan struct{}
+}
+
+// scheduledEvent is an upcoming occurrence of a schedule item
+type scheduledEvent struct {
+	// Index is the item's 1-based position in the schedule, as logged on start
+	Index int          `json:"index"`
+	Due   time.Time    `json:"due"`
+	Item  ScheduleItem `json:"item"`
+	// Result is set when the occurrence already ran early or was skipped
+	Result string `json:"result,omitempty"`
+}
+
+// daemonStatus is the state of a running daemon as reported by the control API
+type daemonStatus struct {
+	PID         int                   `json:"pid"`
+	DryRun      bool                  `json:"dryRun"`
+	Timezone    string                `json:"timezone"`
+	Night       string                `json:"night"`
+	LastRun     time.Time             `json:"lastRun"`
+	Paused      bool                  `json:"paused"`
+	PausedUntil time.Time             `json:"pausedUntil,omitzero"`
+	Schedule    []ScheduleItem        `json:"schedule"`
+	Next        []scheduledEvent      `json:"next"`
+	Results     []executionRecord     `json:"results"`
+	Device      *eightsleep.PodStatus `json:"device,omitempty"`
+	DeviceError string                `json:"deviceError,omitempty"`
+}
+
+// controlAPI serves the daemon's control API, running every call that touches the schedule on
+// the scheduler's goroutine
+type controlAPI struct {
+	calls chan<- controlCall
+}
+
+// controlAddress returns the network and address of the control API, or ok false when disabled
+func controlAddress() (network, address string, ok bool, err error) {
+	listen := currentDaemonSettings().config.GetString("daemon.listen")
+	switch {
+	case listen == "off":
+		return "", "", false, nil
+	case listen == "":
+		dir, err := configDir()
+		if err != nil {
+			return "", "", false, err
+		}
+		return "unix", filepath.Join(dir, "daemon.sock"), true, nil
+	case strings.HasPrefix(listen, "unix:"):
+		return "unix", strings.TrimPrefix(listen, "unix:"), true, nil
+	case strings.Contains(listen, "/"):
+		return "unix", listen, true, nil
+	default:
+		// the API has no authentication, so it must not be reachable from other hosts
+		host, _, err := net.SplitHostPort(listen)
+		if err != nil {
+			return "", "", false, fmt.Errorf("invalid control API address '%s': %w", listen, err)
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return "", "", false, fmt.Errorf("control API address '%s' must be a loopback address such as 127.0.0.1", listen)
+		}
+		return "tcp", listen, true, nil
+	}
+}
+
+// listenUnix listens on a unix socket only the user can connect to. The socket is created in a
+// private directory and moved into place once secured, so it is never reachable with looser
+// permissions. The returned function closes the listener and removes the socket.
+func listenUnix(address string) (net.Listener, func(), error) {
+	dir, err := os.MkdirTemp(filepath.Dir(address), ".clim8-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "daemon.sock")
+	ln, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, nil, err
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmp, 0600); err != nil {
+		ln.Close()
+		return nil, nil, err
+	}
+	// the PID file guarantees no other daemon is serving on a socket left behind
+	if err := os.Rename(tmp, address); err != nil {
+		ln.Close()
+		return nil, nil, err
+	}
+	return ln, func() {
+		ln.Close()
+		os.Remove(address)
+	}, nil
+}
+
+// serveControl starts the control API, the returned function shuts it down
+func serveControl(ctx context.Context, calls chan<- controlCall) (func(), error) {
+	network, address, ok, err := controlAddress()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return func() {}, nil
+	}
+
+	var ln net.Listener
+	closeListener := func() {}
+	if network == "unix" {
+		ln, closeListener, err = listenUnix(address)
+	} else {
+		ln, err = net.Listen(network, address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for control API: %w", err)
+	}
+
+	api := &controlAPI{calls: calls}
+	srv := &http.Server{
+		Handler:           api.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Control API stopped", "err", err)
+		}
+	}()
+	logger.Info("Control API listening", "address", address)
+
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+		closeListener()
+	}, nil
+}
+
+func (api *controlAPI) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/status", api.status)
+	mux.HandleFunc("GET /v1/schedule", api.schedule)
+	mux.HandleFunc("GET /v1/events", api.events)
+	mux.HandleFunc("GET /v1/results", api.results)
+	mux.HandleFunc("GET /v1/device", api.device)
+	mux.HandleFunc("POST /v1/pause", api.pause)
+	mux.HandleFunc("POST /v1/resume", api.resume)
+	mux.HandleFunc("POST /v1/skip", api.skip)
+	mux.HandleFunc("POST /v1/trigger", api.trigger)
+	mux.HandleFunc("POST /v1/reload", api.reload)
+	mux.HandleFunc("GET /metrics", serveMetrics)
+	return mux
+}
+
+// call runs fn on the scheduler's goroutine and waits for it to return
+func (api *controlAPI) call(ctx context.Context, fn func(ctx context.Context, s *scheduler)) error {
+	call := controlCall{fn: fn, done: make(chan struct{})}
+	select {
+	case api.calls <- call:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	<-call.done
+	return nil
+}
+
+func (api *controlAPI) status(w http.ResponseWriter, r *http.Request) {
+	next, ok := queryCount(w, r, "next", defaultUpcomingEvents)
+	if !ok {
+		return
+	}
+
+	status := daemonStatus{
+		PID:      os.Getpid(),
+		DryRun:   currentDaemonSettings().dryRun,
+		Timezone: daemonLocation.String(),
+	}
+	err := api.call(r.Context(), func(ctx context.Context, s *scheduler) {
+		now := daemonNow()
+		status.Night = nightOf(now)
+		status.LastRun = s.state.LastRun
+		status.Paused = s.state.Paused
+		status.PausedUntil = s.state.PausedUntil
+		status.Schedule = append([]ScheduleItem(nil), s.schedule...)
+		status.Next = s.upcoming(now, next)
+		status.Results = s.recentResults(defaultRecentResults)
+	})
+	if err != nil {
+		writeControlError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	// the device is polled outside the scheduler's goroutine so a slow API does not hold it up
+	if _, device, err := pollDevice(r.Context()); err != nil {
+		status.DeviceError = err.Error()
+	} else {
+		status.Device = device
+	}
+
+	writeControlJSON(w, status)
+}
+
+func (api *controlAPI) schedule(w http.ResponseWriter, r *http.Request) {
+	var schedule []ScheduleItem
+	if err := api.call(r.Context(), func(ctx context.Context, s *scheduler) {
+		schedule = append([]ScheduleItem(nil), s.schedule...)
+	}); err != nil {
+		writeControlError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeControlJSON(w, schedule)
+}
+
+func (api *controlAPI) events(w http.ResponseWriter, r *http.Request) {
+	n, ok := queryCount(w, r, "n", defaultUpcomingEvents)
+	if !ok {
+		return
+	}
+	var events []scheduledEvent
+	if err := api.call(r.Context(), func(ctx context.Context, s *scheduler) {
+		events = s.upcoming(daemonNow(), n)
+	}); err != nil {
+		writeControlError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeControlJSON(w, events)
+}
+
+func (api *controlAPI) results(w http.ResponseWriter, r *http.Request) {
+	n, ok := queryCount(w, r, "n", defaultRecentResults)
+	if !ok {
+		return
+	}
+	var results []executionRecord
+	if err := api.call(r.Context(), func(ctx context.Context, s *scheduler) {
+		results = s.recentResults(n)
+	}); err != nil {
+		writeControlError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeControlJSON(w, results)
+}
+
+func (api *controlAPI) device(w http.ResponseWriter, r *http.Request) {
+	_, status, err := pollDevice(r.Context())
+	if err != nil {
+		writeControlError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeControlJSON(w, status)
+}
+
+func (api *controlAPI) pause(w http.ResponseWriter, r *http.Request) {
+	var until time.Time
+	if d := r.URL.Query().Get("for"); d != "" {
+		duration, err := time.ParseDuration(d)
+		if err != nil || duration <= 0 {
+			writeControlError(w, http.StatusBadRequest, fmt.Errorf("invalid pause duration '%s'", d))
+			return
+		}
+		until = daemonNow().Add(duration)
+	}
+
+	var status daemonStatus
+	if err := api.call(r.Context(), func(ctx context.Context, s *scheduler) {
+		s.state.Paused, s.state.PausedUntil = true, until
+		s.saveState()
+		if until.IsZero() {
+			logger.Info("Schedule paused")
+		} else {
+			logger.Info("Schedule paused", "until", until.Format(time.DateTime))
+		}
+		status.Paused, status.PausedUntil = s.state.Paused, s.state.PausedUntil
+	}); err != nil {
+		writeControlError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeControlJSON(w, status)
+}
+
+func (api *controlAPI) resume(w http.ResponseWriter, r *http.Request) {
+	var status daemonStatus
+	if err := api.call(r.Context(), func(ctx context.Context, s *scheduler) {
+		if s.state.Paused {
+			logger.Info("Schedule resumed")
+		}
+		s.state.Paused, s.state.PausedUntil = false, time.Time{}
+		s.saveState()
+		status.Paused = s.state.Paused
+	}); err != nil {
+		writeControlError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeControlJSON(w, status)
+}
+
+func (api *controlAPI) skip(w http.ResponseWriter, r *http.Request) {
+	var event scheduledEvent
+	var skipErr error
+	if err := api.call(r.Context(), func(ctx context.Context, s *scheduler) {
+		event, skipErr = s.skipNext(daemonNow())
+	}); err != nil {
+		writeControlError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if skipErr != nil {
+		writeControlError(w, http.StatusConflict, skipErr)
+		return
+	}
+	writeControlJSON(w, event)
+}
+
+func (api *controlAPI) trigger(w http.ResponseWriter, r *http.Request) {
+	ref := r.URL.Query().Get("item")
+	if ref == "" {
+		writeControlError(w, http.StatusBadRequest, fmt.Errorf("item is required"))
+		return
+	}
+
+	var event scheduledEvent
+	var triggerErr error
+	if err := api.call(r.Context(), func(ctx context.Context, s *scheduler) {
+		event, triggerErr = s.trigger(ctx, ref)
+	}); err != nil {
+		writeControlError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	switch {
+	case errors.Is(triggerErr, errUnknownItem):
+		writeControlError(w, http.StatusNotFound, triggerErr)
+	case triggerErr != nil && event.Index == 0:
+		writeControlError(w, http.StatusBadRequest, triggerErr)
+	case triggerErr != nil:
+		writeControlError(w, http.StatusBadGateway, triggerErr)
+	default:
+		writeControlJSON(w, event)
+	}
+}
+
+func (api *controlAPI) reload(w http.ResponseWriter, r *http.Request) {
+	var schedule []ScheduleItem
+	var reloadErr error
+	if err := api.call(r.Context(), func(ctx context.Context, s *scheduler) {
+		reloadErr = s.reload("control API")
+		schedule = append([]ScheduleItem(nil), s.schedule...)
+	}); err != nil {
+		writeControlError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if reloadErr != nil {
+		logger.Error("Failed to reload schedule, keeping current schedule", "err", reloadErr)
+		writeControlError(w, http.StatusUnprocessableEntity, reloadErr)
+		return
+	}
+	writeControlJSON(w, schedule)
+}
+
+// queryCount parses a positive count from the query, writing an error response if it is invalid
+func queryCount(w http.ResponseWriter, r *http.Request, key string, def int) (int, bool) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		writeControlError(w, http.StatusBadRequest, fmt.Errorf("invalid %s '%s' (must be a positive number)", key, value))
+		return 0, false
+	}
+	return n, true
+}
+
+func writeControlJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeControlError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// errUnknownItem is returned when a control API call references an item not in the schedule
+var errUnknownItem = errors.New("no such schedule item")
+
+// upcoming returns the next n occurrences of the schedule's items after now
+func (s *scheduler) upcoming(now time.Time, n int) []scheduledEvent {
+	var events []scheduledEvent
+	for i, item := range s.schedule {
+		t := now
+		for range n {
+			due, ok := nextOccurrence(item, t)
+			if !ok {
+				break
+			}
+			event := scheduledEvent{Index: i + 1, Due: due, Item: item}
+			if record, ok := s.state.Executions[executionKey(due, item)]; ok {
+				event.Result = record.Result
+			}
+			events = append(events, event)
+			t = due
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Due.Before(events[j].Due) })
+	return events[:min(n, len(events))]
+}
+
+// recentResults returns the n most recent execution records, newest first
+func (s *scheduler) recentResults(n int) []executionRecord {
+	results := make([]executionRecord, 0, len(s.state.Executions))
+	for _, record := range s.state.Executions {
+		results = append(results, record)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].RanAt.After(results[j].RanAt) })
+	return results[:min(n, len(results))]
+}
+
+// skipNext records the next occurrence that has not run yet as skipped
+func (s *scheduler) skipNext(now time.Time) (scheduledEvent, error) {
+	for _, event := range s.upcoming(now, len(s.schedule)+1) {
+		if event.Result != "" {
+			continue
+		}
+		logger.Info("Skipping next scheduled action", "due", event.Due.Format(time.DateTime), "action", event.Item.Action)
+		s.record(executionKey(event.Due, event.Item), occurrence{item: event.Item, due: event.Due}, resultSkipped, nil)
+		s.saveState()
+		event.Result = resultSkipped
+		return event, nil
+	}
+	return scheduledEvent{}, fmt.Errorf("no upcoming scheduled action to skip")
+}
+
+// trigger runs an item right away, outside of its schedule. The item is referenced by its 1-based
+// index or its time, which must be unique. Triggered runs are not recorded, the item still runs
+// at its scheduled time.
+func (s *scheduler) trigger(ctx context.Context, ref string) (scheduledEvent, error) {
+	index := -1
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(s.schedule) {
+			return scheduledEvent{}, fmt.Errorf("%w: %s", errUnknownItem, ref)
+		}
+		index = n - 1
+	} else {
+		for i, item := range s.schedule {
+			if item.Time != ref {
+				continue
+			}
+			if index >= 0 {
+				return scheduledEvent{}, fmt.Errorf("several items at %s, use the item's index", ref)
+			}
+			index = i
+		}
+		if index < 0 {
+			return scheduledEvent{}, fmt.Errorf("%w: %s", errUnknownItem, ref)
+		}
+	}
+
+	item := s.schedule[index]
+	if item.Action == "ramp" {
+		return scheduledEvent{}, fmt.Errorf("ramp actions cannot be triggered")
+	}
+
+	event := scheduledEvent{Index: index + 1, Due: daemonNow(), Item: item}
+	logger.Info("Triggering action", append([]any{"item", event.Index, "action", item.Action}, scheduleItemDetails(item)...)...)
+	if err := s.action(ctx, item); err != nil {
+		logger.Error("Failed to execute action", "action", item.Action, "err", err)
+		event.Result = resultFailed
+		return event, err
+	}
+	event.Result = resultOK
+	return event, nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestControlAddress(t *testing.T) {
+	tests := []struct {
+		listen  string
+		network string
+		ok      bool
+		wantErr bool
+	}{
+		{listen: "off"},
+		{listen: "unix:/run/clim8.sock", network: "unix", ok: true},
+		{listen: "/run/clim8.sock", network: "unix", ok: true},
+		{listen: "127.0.0.1:8787", network: "tcp", ok: true},
+		{listen: "[::1]:8787", network: "tcp", ok: true},
+		{listen: "localhost:8787", network: "tcp", ok: true},
+		{listen: "0.0.0.0:8787", wantErr: true},
+		{listen: ":8787", wantErr: true},
+		{listen: "192.168.1.10:8787", wantErr: true},
+		{listen: "pod.example.com:8787", wantErr: true},
+		{listen: "8787", wantErr: true},
+	}
+	t.Cleanup(func() { loadedDaemonSettings.Store(nil) })
+	for _, tt := range tests {
+		v := viper.New()
+		v.Set("daemon.listen", tt.listen)
+		settings := *defaultDaemonSettings
+		settings.config = v
+		loadedDaemonSettings.Store(&settings)
+
+		network, _, ok, err := controlAddress()
+		if (err != nil) != tt.wantErr || network != tt.network || ok != tt.ok {
+			t.Errorf("%s: controlAddress() = %q, %v, %v, want %q, %v, error %v", tt.listen, network, ok, err, tt.network, tt.ok, tt.wantErr)
+		}
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := t.TempDir()
+	address := filepath.Join(dir, "daemon.sock")
+	// a socket left behind by a daemon that crashed
+	if err := os.WriteFile(address, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, stop, err := listenUnix(address)
+	if err != nil {
+		t.Fatalf("listenUnix() error = %v", err)
+	}
+	info, err := os.Stat(address)
+	if err != nil || info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0600 {
+		t.Errorf("listenUnix() socket = %v, %v, want a socket with mode 0600", info, err)
+	}
+	conn, err := net.Dial("unix", address)
+	if err != nil {
+		t.Errorf("dial error = %v", err)
+	} else {
+		conn.Close()
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("listenUnix() left %d entries behind, want only the socket", len(entries))
+	}
+
+	stop()
+	if _, err := ln.Accept(); err == nil {
+		t.Error("listener still open after stop")
+	}
+	if _, err := os.Stat(address); !os.IsNotExist(err) {
+		t.Errorf("socket not removed after stop: %v", err)
+	}
+}
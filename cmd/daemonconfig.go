@@ -0,0 +1,174 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// daemonSettings are the config settings the daemon runs with. They are parsed into a fresh viper
+// instance on start and on every reload and swapped in as a whole, so the config is never changed
+// while the scheduler, the control API or the shared client read it.
+type daemonSettings struct {
+	// config is the config the settings were read from, it is not changed once read
+	config      *viper.Viper
+	dryRun      bool
+	syncState   bool
+	catchUp     string
+	dayBoundary string
+}
+
+// defaultDaemonSettings apply until the daemon loaded its settings, e.g. in the client commands
+var defaultDaemonSettings = &daemonSettings{
+	config:      viper.GetViper(),
+	syncState:   true,
+	catchUp:     catchUpLatest,
+	dayBoundary: defaultDayBoundary,
+}
+
+var loadedDaemonSettings atomic.Pointer[daemonSettings]
+
+// currentDaemonSettings returns the settings the daemon currently runs with
+func currentDaemonSettings() *daemonSettings {
+	if settings := loadedDaemonSettings.Load(); settings != nil {
+		return settings
+	}
+	return defaultDaemonSettings
+}
+
+// readDaemonConfig reads the config file into a fresh viper instance, with the same environment
+// variables and flags as the global config
+func readDaemonConfig(file string) (*viper.Viper, error) {
+	v := viper.New()
+	configureEnv(v)
+	bindRootFlags(v)
+	bindDaemonFlags(v)
+	if file != "" {
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+	}
+	// A daemon specific `daemon.timezone` config key takes precedence over `timezone`
+	if tz := v.GetString("daemon.timezone"); tz != "" {
+		v.Set("timezone", tz)
+	}
+	return v, nil
+}
+
+// loadDaemonSettings parses and validates the daemon's settings and schedule from v
+func loadDaemonSettings(v *viper.Viper) (*daemonSettings, []ScheduleItem, error) {
+	settings := &daemonSettings{
+		config:      v,
+		dryRun:      v.GetBool("daemon.dry-run"),
+		syncState:   v.GetBool("daemon.sync-state"),
+		catchUp:     v.GetString("daemon.catch-up"),
+		dayBoundary: v.GetString("daemon.day-boundary"),
+	}
+	if settings.dayBoundary == "" {
+		settings.dayBoundary = defaultDayBoundary
+	}
+	if err := validateCatchUp(settings.catchUp); err != nil {
+		return nil, nil, err
+	}
+	if _, err := parseTime(settings.dayBoundary, time.Now()); err != nil {
+		return nil, nil, fmt.Errorf("invalid day boundary '%s': %w", settings.dayBoundary, err)
+	}
+
+	schedule, err := loadSchedule(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return settings, schedule, nil
+}
+
+// logChanges logs the settings that differ from prev
+func (s *daemonSettings) logChanges(prev *daemonSettings) {
+	changes := []struct {
+		key        string
+		prev, next any
+	}{
+		{"daemon.sync-state", prev.syncState, s.syncState},
+		{"daemon.catch-up", prev.catchUp, s.catchUp},
+		{"daemon.day-boundary", prev.dayBoundary, s.dayBoundary},
+	}
+	for _, c := range changes {
+		if c.prev != c.next {
+			logger.Info("Setting changed", "key", c.key, "from", c.prev, "to", c.next)
+		}
+	}
+}
+
+// clientChanged reports whether the shared client must log in again with the settings
+func (s *daemonSettings) clientChanged(prev *daemonSettings) bool {
+	for _, key := range []string{"email", "password", "unit"} {
+		if s.config.GetString(key) != prev.config.GetString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchConfigFile calls onChange whenever the config file is written or replaced, until ctx is
+// done. It only triggers the reload, the config is read again by the scheduler.
+func watchConfigFile(ctx context.Context, file string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+	// watch the directory, editors often replace the file instead of writing it
+	file = filepath.Clean(file)
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == file && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					onChange()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warn("Config file watcher error", "err", err)
+			}
+		}
+	}()
+	return nil
+}
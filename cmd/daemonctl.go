@@ -0,0 +1,285 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/spf13/cobra"
+)
+
+// daemonStatusCmd represents the daemon status command
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the running daemon's schedule, upcoming events and last results",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		next, _ := cmd.Flags().GetInt("next")
+
+		var status daemonStatus
+		if err := daemonControl(cmd.Context(), http.MethodGet, "/v1/status?next="+strconv.Itoa(next), &status); err != nil {
+			return err
+		}
+
+		if !isStructuredOutput() {
+			switch {
+			case status.Paused && !status.PausedUntil.IsZero():
+				logger.Info("Daemon is PAUSED", "until", status.PausedUntil.Format(time.DateTime))
+			case status.Paused:
+				logger.Info("Daemon is PAUSED")
+			default:
+				logger.Info("Daemon is RUNNING", "pid", status.PID, "night", status.Night, "dry_run", status.DryRun)
+			}
+			if status.Device != nil {
+				for _, side := range []*eightsleep.SideStatus{&status.Device.Left, &status.Device.Right} {
+					state := "off"
+					if side.On {
+						state = "on, " + eightsleep.FormatLevel(side.TargetLevel, status.Device.Unit)
+					}
+					logger.Info(fmt.Sprintf("%s side is %s", side.Side, state))
+				}
+			} else if status.DeviceError != "" {
+				logger.Warn("Failed to get device status", "err", status.DeviceError)
+			}
+		}
+
+		if err := printOutput(status, eventsTable(status.Next)); err != nil {
+			return err
+		}
+		if !isStructuredOutput() && len(status.Results) > 0 {
+			return printOutput(status.Results, resultsTable(status.Results))
+		}
+		return nil
+	},
+}
+
+// daemonPauseCmd represents the daemon pause command
+var daemonPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the running daemon's schedule",
+	Long: `Pause the running daemon's schedule.
+
+Scheduled actions, ramps and state synchronization are skipped while paused, and
+actions that came due are not caught up on resume.`,
+	Example: "  clim8 daemon pause\n  clim8 daemon pause --for 72h",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "/v1/pause"
+		if d, _ := cmd.Flags().GetDuration("for"); d > 0 {
+			path += "?for=" + url.QueryEscape(d.String())
+		}
+		var status daemonStatus
+		if err := daemonControl(cmd.Context(), http.MethodPost, path, &status); err != nil {
+			return err
+		}
+		if status.PausedUntil.IsZero() {
+			logger.Info("Daemon paused")
+		} else {
+			logger.Info("Daemon paused", "until", status.PausedUntil.Format(time.DateTime))
+		}
+		return nil
+	},
+}
+
+// daemonResumeCmd represents the daemon resume command
+var daemonResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the running daemon's schedule",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := daemonControl(cmd.Context(), http.MethodPost, "/v1/resume", nil); err != nil {
+			return err
+		}
+		logger.Info("Daemon resumed")
+		return nil
+	},
+}
+
+// daemonSkipCmd represents the daemon skip command
+var daemonSkipCmd = &cobra.Command{
+	Use:   "skip",
+	Short: "Skip the next scheduled action",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var event scheduledEvent
+		if err := daemonControl(cmd.Context(), http.MethodPost, "/v1/skip", &event); err != nil {
+			return err
+		}
+		logger.Info(fmt.Sprintf("Skipped %s - %s", event.Due.Format("Mon 15:04"), event.Item.Action), scheduleItemDetails(event.Item)...)
+		return nil
+	},
+}
+
+// daemonTriggerCmd represents the daemon trigger command
+var daemonTriggerCmd = &cobra.Command{
+	Use:   "trigger <item>",
+	Short: "Run a schedule item now",
+	Long: `Run a schedule item now.
+
+The item is its number as listed by 'clim8 daemon status' or its time, e.g. 22:15.
+It still runs at its scheduled time as well.`,
+	Example: "  clim8 daemon trigger 2\n  clim8 daemon trigger 22:15",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var event scheduledEvent
+		if err := daemonControl(cmd.Context(), http.MethodPost, "/v1/trigger?item="+url.QueryEscape(args[0]), &event); err != nil {
+			return err
+		}
+		logger.Info(fmt.Sprintf("Triggered %s - %s", event.Item.Time, event.Item.Action), scheduleItemDetails(event.Item)...)
+		return nil
+	},
+}
+
+// daemonReloadCmd represents the daemon reload command
+var daemonReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload the running daemon's schedule from the config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var schedule []ScheduleItem
+		if err := daemonControl(cmd.Context(), http.MethodPost, "/v1/reload", &schedule); err != nil {
+			return err
+		}
+		logger.Info("Schedule reloaded", "items", len(schedule))
+		return nil
+	},
+}
+
+// actionRequests is the most API requests an action makes one after another: a login, turning
+// the pod on and setting its level
+const actionRequests = 3
+
+// controlTimeout is how long a control API call may take. Triggers run their action before
+// responding, and each of its requests may take the client's full timeout.
+const controlTimeout = actionRequests*eightsleep.RequestTimeout + time.Minute
+
+// daemonControl calls the running daemon's control API, decoding the JSON response into out
+func daemonControl(ctx context.Context, method, path string, out any) error {
+	network, address, ok, err := controlAddress()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("control API is disabled (daemon.listen is off)")
+	}
+
+	client := &http.Client{
+		Timeout: controlTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, address)
+			},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, "http://clim8"+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the daemon at %s, is it running? %w", address, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("daemon: %s", apiErr.Error)
+		}
+		return fmt.Errorf("daemon: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func eventsTable(events []scheduledEvent) *tableData {
+	tbl := &tableData{Headers: []string{"#", "Due", "Action", "Details", "Result"}}
+	for _, event := range events {
+		tbl.add(
+			strconv.Itoa(event.Index),
+			event.Due.Format("Mon 2006-01-02 15:04"),
+			event.Item.Action,
+			formatItemDetails(event.Item),
+			event.Result,
+		)
+	}
+	return tbl
+}
+
+func resultsTable(results []executionRecord) *tableData {
+	tbl := &tableData{Headers: []string{"Ran At", "Time", "Action", "Result", "Error"}}
+	for _, record := range results {
+		tbl.add(
+			record.RanAt.Format("Mon 2006-01-02 15:04:05"),
+			record.Time,
+			record.Action,
+			record.Result,
+			record.Error,
+		)
+	}
+	return tbl
+}
+
+// formatItemDetails renders an item's settings besides time and action, e.g. "temperature=68F"
+func formatItemDetails(item ScheduleItem) string {
+	kv := scheduleItemDetails(item)
+	parts := make([]string, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		parts = append(parts, fmt.Sprintf("%v=%v", kv[i], kv[i+1]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func init() {
+	daemonCmd.AddCommand(daemonStatusCmd)
+	daemonCmd.AddCommand(daemonPauseCmd)
+	daemonCmd.AddCommand(daemonResumeCmd)
+	daemonCmd.AddCommand(daemonSkipCmd)
+	daemonCmd.AddCommand(daemonTriggerCmd)
+	daemonCmd.AddCommand(daemonReloadCmd)
+
+	daemonStatusCmd.Flags().IntP("next", "n", defaultUpcomingEvents, "Number of upcoming events to show")
+	daemonPauseCmd.Flags().Duration("for", 0, "Resume automatically after this long, e.g. 72h")
+}
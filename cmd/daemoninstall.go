@@ -0,0 +1,228 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// systemdUnitName is the name of the unit installed by `daemon install`
+const systemdUnitName = "clim8.service"
+
+// startupRequests is how many API requests the daemon makes one after another before it is
+// ready: the login and the profile and devices fetched to learn the pod's timezone
+const startupRequests = 3
+
+// systemdStartTimeout is how long systemd waits for the daemon to become ready, allowing every
+// startup request to take the client's full timeout
+const systemdStartTimeout = startupRequests*eightsleep.RequestTimeout + time.Minute
+
+// systemdWatchdog is how long the scheduler may go without a keep-alive before systemd restarts
+// it. Keep-alives are sent after every wake and every API request of the scheduler loop, so it
+// allows for one request taking the client's full timeout.
+const systemdWatchdog = eightsleep.RequestTimeout + time.Minute
+
+// daemonInstallCmd represents the daemon install command
+var daemonInstallCmd = &cobra.Command{
+	Use:   "install --user [-- daemon flags]",
+	Short: "Install the daemon as a systemd user service",
+	Long: `Install the daemon as a systemd user service.
+
+Writes ~/.config/systemd/user/clim8.service, then enables and (re)starts it.
+The service reads ~/.config/clim8/config.yaml like any other clim8 command, and
+any CLIM8_* environment variables set now are copied into the unit. Flags after
+-- are passed to 'clim8 daemon'.
+
+The service notifies systemd once it is running, reports what it does next as
+its status and is restarted by systemd if the scheduler hangs.`,
+	Example: "  clim8 daemon install --user\n  clim8 daemon install --user -- --metrics-listen 127.0.0.1:9788\n  clim8 daemon install --user --print",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 && cmd.ArgsLenAtDash() != 0 {
+			return fmt.Errorf("unexpected argument '%s', pass daemon flags after --", args[0])
+		}
+		return validateDaemonArgs(args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		user, _ := cmd.Flags().GetBool("user")
+		printOnly, _ := cmd.Flags().GetBool("print")
+
+		if !user {
+			return fmt.Errorf("only user services are supported, use --user")
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the clim8 executable: %w", err)
+		}
+		unit := systemdUnit(exe, args, clim8Environment())
+
+		if printOnly {
+			fmt.Print(unit)
+			return nil
+		}
+		if runtime.GOOS != "linux" {
+			return fmt.Errorf("systemd services are only supported on Linux, use --print to see the unit")
+		}
+
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dir := filepath.Join(home, ".config", "systemd", "user")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create systemd user directory: %w", err)
+		}
+		// the unit may hold credentials from the environment
+		path := filepath.Join(dir, systemdUnitName)
+		if err := os.WriteFile(path, []byte(unit), 0600); err != nil {
+			return fmt.Errorf("failed to write unit: %w", err)
+		}
+		logger.Info("Wrote systemd unit", "file", path)
+
+		for _, systemctlArgs := range [][]string{
+			{"--user", "daemon-reload"},
+			{"--user", "enable", systemdUnitName},
+			{"--user", "restart", systemdUnitName},
+		} {
+			out, err := exec.CommandContext(cmd.Context(), "systemctl", systemctlArgs...).CombinedOutput()
+			if err != nil {
+				return fmt.Errorf("systemctl %s failed: %w: %s", strings.Join(systemctlArgs, " "), err, strings.TrimSpace(string(out)))
+			}
+		}
+
+		logger.Info("Daemon installed and started", "unit", systemdUnitName)
+		logger.Info("Follow its logs with: journalctl --user -u clim8 -f")
+		logger.Info("Keep it running while logged out with: loginctl enable-linger " + os.Getenv("USER"))
+		return nil
+	},
+}
+
+// systemdUnit renders the user unit running `clim8 daemon` with extra args and environment
+func systemdUnit(exe string, args []string, env []string) string {
+	var b strings.Builder
+	b.WriteString(`[Unit]
+Description=Eight Sleep scheduler daemon (clim8)
+Documentation=https://github.com/blacktop/clim8/blob/main/docs/daemon.md
+
+[Service]
+Type=notify
+NotifyAccess=main
+`)
+	command := []string{exe, "daemon"}
+	command = append(command, args...)
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		// command lines expand $VARIABLES, environment assignments do not
+		quoted[i] = systemdQuote(strings.ReplaceAll(arg, "$", "$$"))
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(quoted, " "))
+	b.WriteString("ExecReload=/bin/kill -HUP $MAINPID\n")
+	for _, kv := range env {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(kv))
+	}
+	fmt.Fprintf(&b, `Restart=on-failure
+RestartSec=30
+TimeoutStartSec=%d
+WatchdogSec=%d
+
+[Install]
+WantedBy=default.target
+`, int(systemdStartTimeout.Seconds()), int(systemdWatchdog.Seconds()))
+	return b.String()
+}
+
+// validateDaemonArgs checks that args are flags `clim8 daemon` accepts, so a typo fails now rather
+// than when systemd starts the service
+func validateDaemonArgs(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var flag *pflag.Flag
+		var hasValue bool
+		switch {
+		case strings.HasPrefix(arg, "--") && len(arg) > 2:
+			var name string
+			name, _, hasValue = strings.Cut(arg[2:], "=")
+			flag = lookupDaemonFlag(func(fs *pflag.FlagSet) *pflag.Flag { return fs.Lookup(name) })
+		case strings.HasPrefix(arg, "-") && len(arg) > 1 && arg != "--":
+			hasValue = len(arg) > 2
+			flag = lookupDaemonFlag(func(fs *pflag.FlagSet) *pflag.Flag { return fs.ShorthandLookup(arg[1:2]) })
+		default:
+			return fmt.Errorf("unexpected argument '%s', the daemon only takes flags", arg)
+		}
+		if flag == nil {
+			return fmt.Errorf("unknown daemon flag '%s'", arg)
+		}
+		if !hasValue && flag.NoOptDefVal == "" {
+			if i++; i == len(args) {
+				return fmt.Errorf("daemon flag '%s' needs a value", arg)
+			}
+		}
+	}
+	return nil
+}
+
+// lookupDaemonFlag finds a flag of `clim8 daemon`, including those inherited from the root command
+func lookupDaemonFlag(lookup func(*pflag.FlagSet) *pflag.Flag) *pflag.Flag {
+	if flag := lookup(daemonCmd.LocalFlags()); flag != nil {
+		return flag
+	}
+	return lookup(daemonCmd.InheritedFlags())
+}
+
+// clim8Environment returns the CLIM8_* variables of the current environment as KEY=value
+func clim8Environment() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "CLIM8_") {
+			env = append(env, kv)
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+// systemdQuote quotes a word for a unit file, escaping specifiers
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func init() {
+	daemonCmd.AddCommand(daemonInstallCmd)
+
+	daemonInstallCmd.Flags().Bool("user", false, "Install a systemd user service for the current user")
+	daemonInstallCmd.Flags().Bool("print", false, "Print the unit instead of installing it")
+}
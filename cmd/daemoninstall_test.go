@@ -0,0 +1,27 @@
+package cmd
+
+import "testing"
+
+func TestValidateDaemonArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{},
+		{args: []string{"--metrics-listen", "127.0.0.1:9788"}},
+		{args: []string{"--metrics-listen=127.0.0.1:9788", "--dry-run"}},
+		{args: []string{"--catch-up", "all", "-V"}},
+		{args: []string{"-e", "a@example.com"}},
+		{args: []string{"--timezone", "Europe/Berlin", "--listen", "off"}},
+		{args: []string{"run"}, wantErr: true},
+		{args: []string{"--dry-run", "now"}, wantErr: true},
+		{args: []string{"--bogus"}, wantErr: true},
+		{args: []string{"-z"}, wantErr: true},
+		{args: []string{"--catch-up"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := validateDaemonArgs(tt.args); (err != nil) != tt.wantErr {
+			t.Errorf("validateDaemonArgs(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+		}
+	}
+}
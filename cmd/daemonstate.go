@@ -0,0 +1,138 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// execution results
+const (
+	resultOK      = "ok"
+	resultFailed  = "failed"
+	resultSkipped = "skipped"
+)
+
+// executionRecord is the outcome of running a schedule item
+type executionRecord struct {
+	Time   string    `json:"time"`
+	Action string    `json:"action"`
+	Due    time.Time `json:"due"`
+	RanAt  time.Time `json:"ranAt"`
+	Result string    `json:"result"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// done reports whether the item ran or was deliberately skipped, failed items may run again
+func (r executionRecord) done() bool {
+	return r.Result == resultOK || r.Result == resultSkipped
+}
+
+// daemonState is what the daemon persists across restarts
+type daemonState struct {
+	// LastRun is when items were last run, items due since then run on the next start
+	LastRun time.Time `json:"lastRun"`
+	// Executions are keyed by executionKey
+	Executions map[string]executionRecord `json:"executions"`
+	// Paused skips scheduled items until resumed, or until PausedUntil when set
+	Paused      bool      `json:"paused,omitempty"`
+	PausedUntil time.Time `json:"pausedUntil,omitzero"`
+}
+
+// stateFile returns the path the daemon state is persisted to
+func stateFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon-state.json"), nil
+}
+
+// loadDaemonState reads the state persisted by a previous run, a missing file is an empty state
+func loadDaemonState() (*daemonState, error) {
+	state := &daemonState{Executions: make(map[string]executionRecord)}
+
+	path, err := stateFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read daemon state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse daemon state %s: %w", path, err)
+	}
+	if state.Executions == nil {
+		state.Executions = make(map[string]executionRecord)
+	}
+	state.rekey()
+	return state, nil
+}
+
+// rekey moves executions to the key of the night they are due in. Older versions keyed them by
+// calendar date, and changing the day boundary moves items between nights, so without it an item
+// that already ran could run again.
+func (s *daemonState) rekey() {
+	for key, record := range s.Executions {
+		if record.Due.IsZero() || len(key) < len("2006-01-02") {
+			continue
+		}
+		night := nightOf(record.Due.In(daemonLocation))
+		if key[:len(night)] == night {
+			continue
+		}
+		delete(s.Executions, key)
+		next := night + key[len(night):]
+		if existing, ok := s.Executions[next]; ok && existing.RanAt.After(record.RanAt) {
+			continue
+		}
+		s.Executions[next] = record
+	}
+}
+
+// save writes the state atomically, so a crash never leaves a truncated file
+func (s *daemonState) save() error {
+	path, err := stateFile()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal daemon state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write daemon state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write daemon state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// withDaemonLocation evaluates the schedule in loc during the test
+func withDaemonLocation(t *testing.T, loc *time.Location) {
+	prev := daemonLocation
+	daemonLocation = loc
+	t.Cleanup(func() { daemonLocation = prev })
+}
+
+func TestLoadDaemonState(t *testing.T) {
+	withDaemonLocation(t, time.UTC)
+	tests := []struct {
+		name       string
+		data       string // empty for no state file
+		executions int
+		lastRun    time.Time
+		paused     bool
+		wantErr    bool
+	}{
+		{name: "missing"},
+		{
+			name:       "saved",
+			data:       `{"lastRun":"2024-03-01T22:00:01Z","executions":{"2024-03-01-22:00-on":{"time":"22:00","action":"on","due":"2024-03-01T22:00:00Z","ranAt":"2024-03-01T22:00:01Z","result":"ok"}},"paused":true}`,
+			executions: 1,
+			lastRun:    time.Date(2024, 3, 1, 22, 0, 1, 0, time.UTC),
+			paused:     true,
+		},
+		{name: "no executions", data: `{"lastRun":"2024-03-01T22:00:01Z"}`, lastRun: time.Date(2024, 3, 1, 22, 0, 1, 0, time.UTC)},
+		{name: "corrupt", data: `{"lastRun":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		if tt.data != "" {
+			dir := filepath.Join(home, ".config", "clim8")
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "daemon-state.json"), []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		state, err := loadDaemonState()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: loadDaemonState() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if state.Executions == nil || len(state.Executions) != tt.executions || !state.LastRun.Equal(tt.lastRun) || state.Paused != tt.paused {
+			t.Errorf("%s: loadDaemonState() = %+v", tt.name, state)
+		}
+	}
+}
+
+func TestDaemonStateSave(t *testing.T) {
+	withDaemonLocation(t, time.UTC)
+	t.Setenv("HOME", t.TempDir())
+
+	due := time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)
+	state := &daemonState{
+		LastRun:     due.Add(time.Second),
+		Executions:  map[string]executionRecord{"2024-03-01-22:00-on": {Time: "22:00", Action: "on", Due: due, RanAt: due.Add(time.Second), Result: resultFailed, Error: "offline"}},
+		Paused:      true,
+		PausedUntil: due.Add(72 * time.Hour),
+	}
+	if err := state.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	got, err := loadDaemonState()
+	if err != nil {
+		t.Fatalf("loadDaemonState() error = %v", err)
+	}
+	record := got.Executions["2024-03-01-22:00-on"]
+	if !got.LastRun.Equal(state.LastRun) || !got.Paused || !got.PausedUntil.Equal(state.PausedUntil) ||
+		record.Result != resultFailed || record.Error != "offline" || !record.Due.Equal(due) || record.done() {
+		t.Errorf("loadDaemonState() after save = %+v", got)
+	}
+}
+
+func TestPruneExecuted(t *testing.T) {
+	now := time.Date(2024, 3, 3, 22, 0, 0, 0, time.UTC)
+	s := &scheduler{state: &daemonState{Executions: map[string]executionRecord{
+		"tonight":       {Due: now.Add(-time.Hour)},
+		"yesterday":     {Due: now.Add(-24 * time.Hour)},
+		"cutoff":        {Due: now.Add(-maxCatchUp).AddDate(0, 0, -1)},
+		"before cutoff": {Due: now.Add(-maxCatchUp).AddDate(0, 0, -1).Add(-time.Second)},
+		"last week":     {Due: now.AddDate(0, 0, -7)},
+	}}}
+	s.pruneExecuted(now)
+	for key, want := range map[string]bool{"tonight": true, "yesterday": true, "cutoff": true, "before cutoff": false, "last week": false} {
+		if _, ok := s.state.Executions[key]; ok != want {
+			t.Errorf("pruneExecuted() kept %q = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestRekey(t *testing.T) {
+	withDaemonLocation(t, time.UTC)
+
+	after := time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC)
+	state := &daemonState{Executions: map[string]executionRecord{
+		// keyed by calendar date before nights were introduced
+		"2024-03-02-06:00-off":    {Time: "06:00", Action: "off", Due: after, RanAt: after, Result: resultOK},
+		"2024-03-02-02:00-ramp-1": {Time: "02:00", Action: "ramp", Due: after.Add(-4 * time.Hour), RanAt: after, Result: resultOK},
+		"2024-03-01-22:00-on":     {Time: "22:00", Action: "on", Due: after.Add(-8 * time.Hour), Result: resultOK},
+		// a record without a due time cannot be placed in a night
+		"2024-03-02-07:00-off": {Time: "07:00", Action: "off", Result: resultOK},
+		// the calendar date key collides with a newer record of the night
+		"2024-03-02-05:00-temp": {Time: "05:00", Action: "temp", Due: after.Add(-time.Hour), RanAt: after, Result: resultFailed},
+		"2024-03-01-05:00-temp": {Time: "05:00", Action: "temp", Due: after.Add(-time.Hour), RanAt: after.Add(time.Minute), Result: resultOK},
+	}}
+	state.rekey()
+
+	want := map[string]string{
+		"2024-03-01-06:00-off":    resultOK,
+		"2024-03-01-02:00-ramp-1": resultOK,
+		"2024-03-01-22:00-on":     resultOK,
+		"2024-03-02-07:00-off":    resultOK,
+		"2024-03-01-05:00-temp":   resultOK,
+	}
+	if len(state.Executions) != len(want) {
+		t.Errorf("rekey() = %v, want keys %v", state.Executions, want)
+	}
+	for key, result := range want {
+		if record, ok := state.Executions[key]; !ok || record.Result != result {
+			t.Errorf("rekey() %q = %+v, %v, want result %s", key, record, ok, result)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dayMask is a set of nights named after the day they start on, bit n is set for time.Weekday(n)
+type dayMask uint8
+
+// Weekdays and weekends are the nights before those mornings, so a 06:00 item on weekdays runs
+// from Monday to Friday morning and a 09:00 item on weekends on Saturday and Sunday morning.
+const (
+	weekdays dayMask = 1<<time.Sunday | 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday
+	weekends dayMask = 1<<time.Friday | 1<<time.Saturday
+	everyDay         = weekdays | weekends
+)
+
+var dayNames = map[string]dayMask{
+	"sun": 1 << time.Sunday, "sunday": 1 << time.Sunday,
+	"mon": 1 << time.Monday, "monday": 1 << time.Monday,
+	"tue": 1 << time.Tuesday, "tues": 1 << time.Tuesday, "tuesday": 1 << time.Tuesday,
+	"wed": 1 << time.Wednesday, "wednesday": 1 << time.Wednesday,
+	"thu": 1 << time.Thursday, "thur": 1 << time.Thursday, "thurs": 1 << time.Thursday, "thursday": 1 << time.Thursday,
+	"fri": 1 << time.Friday, "friday": 1 << time.Friday,
+	"sat": 1 << time.Saturday, "saturday": 1 << time.Saturday,
+	"weekdays": weekdays, "weekday": weekdays,
+	"weekends": weekends, "weekend": weekends,
+	"daily": everyDay, "everyday": everyDay,
+}
+
+// parseDays parses day names such as "mon", "friday", "weekdays" or "weekends", each of which may
+// also be a comma separated list. No days means every day.
+func parseDays(days []string) (dayMask, error) {
+	if len(days) == 0 {
+		return everyDay, nil
+	}
+	var mask dayMask
+	for _, day := range days {
+		for _, name := range strings.Split(day, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			m, ok := dayNames[name]
+			if !ok {
+				return 0, fmt.Errorf("unknown day '%s' (expected mon..sun, weekdays, weekends or daily)", name)
+			}
+			mask |= m
+		}
+	}
+	if mask == 0 {
+		return 0, fmt.Errorf("no days given")
+	}
+	return mask, nil
+}
+
+func (m dayMask) has(day time.Weekday) bool {
+	return m&(1<<day) != 0
+}
+
+// names returns the days in the mask, using "weekdays" and "weekends" where they apply
+func (m dayMask) names() []string {
+	switch m {
+	case everyDay:
+		return nil
+	case weekdays:
+		return []string{"weekdays"}
+	case weekends:
+		return []string{"weekends"}
+	}
+	var names []string
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if m.has(day) {
+			names = append(names, strings.ToLower(day.String()[:3]))
+		}
+	}
+	return names
+}
+
+// runsOn reports whether the item is scheduled on the night that starts on the given day
+func (item ScheduleItem) runsOn(day time.Weekday) bool {
+	mask, err := parseDays(item.Days)
+	if err != nil {
+		return false
+	}
+	return mask.has(day)
+}
+
+// Items returns the daily schedule and the named schedule sets as a single list. A set's name
+// selects the days its items run on, e.g. "weekdays", "weekends" or "sat,sun".
+func (c ScheduleConfig) Items() ([]ScheduleItem, error) {
+	items := append([]ScheduleItem(nil), c.Schedule...)
+
+	names := make([]string, 0, len(c.Schedules))
+	for name := range c.Schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		setDays, err := parseDays([]string{name})
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule set '%s': %w", name, err)
+		}
+		for i, item := range c.Schedules[name] {
+			itemDays, err := parseDays(item.Days)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schedule set '%s' item %d: %w", name, i, err)
+			}
+			days := setDays & itemDays
+			if days == 0 {
+				return nil, fmt.Errorf("invalid schedule set '%s' item %d: days %s never fall on %s",
+					name, i, strings.Join(item.Days, ","), name)
+			}
+			item.Days = days.names()
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		days    []string
+		want    dayMask
+		wantErr bool
+	}{
+		{days: nil, want: everyDay},
+		{days: []string{"mon"}, want: 1 << time.Monday},
+		{days: []string{"Friday"}, want: 1 << time.Friday},
+		{days: []string{"mon", "wed"}, want: 1<<time.Monday | 1<<time.Wednesday},
+		{days: []string{"mon, tue ,"}, want: 1<<time.Monday | 1<<time.Tuesday},
+		{days: []string{"weekdays"}, want: weekdays},
+		{days: []string{"weekends", "fri"}, want: weekends | 1<<time.Friday},
+		{days: []string{"daily"}, want: everyDay},
+		{days: []string{"mon", "mon"}, want: 1 << time.Monday},
+		{days: []string{"someday"}, wantErr: true},
+		{days: []string{"mon", "funday"}, wantErr: true},
+		{days: []string{""}, wantErr: true},
+		{days: []string{" , "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDays(tt.days)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDays(%q) error = %v, wantErr %v", tt.days, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("parseDays(%q) = %07b, want %07b", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestDayMaskNames(t *testing.T) {
+	tests := []struct {
+		mask dayMask
+		want []string
+	}{
+		{mask: everyDay, want: nil},
+		{mask: weekdays, want: []string{"weekdays"}},
+		{mask: weekends, want: []string{"weekends"}},
+		{mask: 1<<time.Sunday | 1<<time.Friday, want: []string{"sun", "fri"}},
+	}
+	for _, tt := range tests {
+		if got := tt.mask.names(); !slices.Equal(got, tt.want) {
+			t.Errorf("%07b.names() = %q, want %q", tt.mask, got, tt.want)
+		}
+		// the names parse back to the same days
+		if got, err := parseDays(tt.mask.names()); err != nil || got != tt.mask {
+			t.Errorf("parseDays(%07b.names()) = %07b, %v", tt.mask, got, err)
+		}
+	}
+}
+
+func TestScheduleConfigItems(t *testing.T) {
+	config := ScheduleConfig{
+		Schedule: []ScheduleItem{{Time: "22:00", Action: "on"}},
+		Schedules: map[string][]ScheduleItem{
+			"weekends": {{Time: "09:00", Action: "off"}, {Time: "10:00", Action: "off", Days: []string{"sat"}}},
+		},
+	}
+	items, err := config.Items()
+	if err != nil {
+		t.Fatalf("Items() error = %v", err)
+	}
+	if len(items) != 3 || items[0].Days != nil || !slices.Equal(items[1].Days, []string{"weekends"}) || !slices.Equal(items[2].Days, []string{"sat"}) {
+		t.Errorf("Items() = %+v", items)
+	}
+
+	config.Schedules = map[string][]ScheduleItem{"weekends": {{Time: "09:00", Action: "off", Days: []string{"mon"}}}}
+	if _, err := config.Items(); err == nil {
+		t.Error("Items() with days outside the set = nil error, want error")
+	}
+	config.Schedules = map[string][]ScheduleItem{"someday": {{Time: "09:00", Action: "off"}}}
+	if _, err := config.Items(); err == nil {
+		t.Error("Items() with an unknown set = nil error, want error")
+	}
+}
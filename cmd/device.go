@@ -0,0 +1,80 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// deviceCmd represents the device command
+var deviceCmd = &cobra.Command{
+	Use:   "device",
+	Short: "Show Eight Sleep device details",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetBool("verbose") {
+			logger.SetLevel(log.DebugLevel)
+		}
+
+		cli, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer cli.Stop()
+
+		devices := cli.Devices()
+		caps := cli.Capabilities()
+
+		tbl := &tableData{Headers: []string{"Device", "Key", "Value"}}
+		for _, d := range devices {
+			for _, kv := range [][2]string{
+				{"Model", d.ModelString},
+				{"Hub Serial", d.HubSerial},
+				{"Firmware", d.FirmwareVersion},
+				{"Online", fmt.Sprint(d.Online)},
+				{"Last Heard", d.LastHeard.Local().Format(time.DateTime)},
+				{"Timezone", d.Timezone},
+				{"Has Water", fmt.Sprint(d.HasWater)},
+				{"Needs Priming", fmt.Sprint(d.NeedsPriming)},
+				{"Last Prime", d.LastPrime.Local().Format(time.DateTime)},
+				{"Wi-Fi", fmt.Sprintf("%s (%d%%)", d.WifiInfo.Ssid, d.WifiInfo.SignalStrength)},
+				{"Cooling", fmt.Sprint(caps.Has(eightsleep.CapCooling))},
+				{"Base", fmt.Sprint(caps.HasBase())},
+				{"Audio", fmt.Sprint(caps.Has(eightsleep.CapAudio))},
+			} {
+				tbl.add(d.ID, kv[0], kv[1])
+			}
+		}
+
+		return printOutput(devices, tbl)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deviceCmd)
+}
@@ -25,8 +25,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 
-	"github.com/alecthomas/chroma/v2/quick"
 	"github.com/blacktop/clim8/pkg/eightsleep"
 	"github.com/charmbracelet/log"
 	"github.com/spf13/cobra"
@@ -36,45 +36,98 @@ import (
 // featsCmd represents the feats command
 var featsCmd = &cobra.Command{
 	Use:   "feats",
-	Short: "Dump release features",
-	Args:  cobra.NoArgs,
+	Short: "Dump release features and device capabilities",
+	Long: `Dump release features and device capabilities.
+
+With --diff the capabilities are compared to the ones saved by the previous --diff run
+and then saved to ~/.config/clim8/capabilities.json for the next one.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if viper.GetBool("verbose") {
 			logger.SetLevel(log.DebugLevel)
 		}
 
-		cli, err := eightsleep.NewClient(
-			viper.GetString("email"),
-			viper.GetString("password"),
-			"America/New_York",
-		)
+		showDiff, _ := cmd.Flags().GetBool("diff")
+
+		cli, err := newClient(cmd.Context())
 		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
+			return err
 		}
 		defer cli.Stop()
 
-		if err := cli.Start(cmd.Context()); err != nil {
-			return fmt.Errorf("failed to start client: %w", err)
-		}
-
-		feats, err := cli.GetReleaseFeatures(cmd.Context())
+		caps, err := cli.GetCapabilities(cmd.Context())
 		if err != nil {
 			return err
 		}
 
-		logger.Info("FEATURES")
-		jsonData, err := json.MarshalIndent(feats, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal json: %v", err)
-		}
-		if err := quick.Highlight(os.Stdout, string(jsonData)+"\n", "json", "terminal256", "nord"); err != nil {
-			return fmt.Errorf("failed to highlight json: %v", err)
+		if showDiff {
+			dir, err := configDir()
+			if err != nil {
+				return err
+			}
+			capsFile := filepath.Join(dir, "capabilities.json")
+
+			prev, err := loadCapabilities(capsFile)
+			if err != nil {
+				return err
+			}
+			added, removed := caps.Diff(prev)
+			if len(added) == 0 && len(removed) == 0 && !isStructuredOutput() {
+				logger.Info("No capability changes since last run")
+			}
+			diff := struct {
+				Added   []eightsleep.Capability `json:"added"`
+				Removed []eightsleep.Capability `json:"removed"`
+			}{added, removed}
+			tbl := &tableData{Headers: []string{"Change", "Capability", "Source"}}
+			for _, cap := range added {
+				tbl.add("+", string(cap), caps[cap])
+			}
+			for _, cap := range removed {
+				tbl.add("-", string(cap), prev[cap])
+			}
+			if err := printOutput(diff, tbl); err != nil {
+				return err
+			}
+			return saveCapabilities(capsFile, caps)
 		}
 
-		return nil
+		tbl := &tableData{Headers: []string{"Capability", "Source"}}
+		for _, cap := range caps.List() {
+			tbl.add(string(cap), caps[cap])
+		}
+		return printOutput(caps, tbl)
 	},
 }
 
+func loadCapabilities(path string) (eightsleep.Capabilities, error) {
+	caps := make(eightsleep.Capabilities)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return caps, nil
+		}
+		return nil, fmt.Errorf("failed to read previous capabilities: %w", err)
+	}
+	if err := json.Unmarshal(data, &caps); err != nil {
+		return nil, fmt.Errorf("failed to parse previous capabilities: %w", err)
+	}
+	return caps, nil
+}
+
+func saveCapabilities(path string, caps eightsleep.Capabilities) error {
+	data, err := json.MarshalIndent(caps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal capabilities: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save capabilities: %w", err)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(featsCmd)
+
+	featsCmd.Flags().Bool("diff", false, "Show capabilities added or removed since the last --diff run")
 }
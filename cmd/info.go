@@ -23,6 +23,9 @@ package cmd
 
 import (
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/blacktop/clim8/pkg/eightsleep"
 	"github.com/charmbracelet/log"
@@ -34,43 +37,80 @@ import (
 var infoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Show Eight Sleep Info",
-	Args:  cobra.NoArgs,
+	Long: fmt.Sprintf(`Show a read-only report of your Eight Sleep account.
+
+Sections: %s`, infoSectionNames()),
+	Example: "  clim8 info\n  clim8 info --only trends,routines\n  clim8 info -o json",
+	Args:    cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if viper.GetBool("verbose") {
 			logger.SetLevel(log.DebugLevel)
 		}
 
-		cli, err := eightsleep.NewClient(
-			viper.GetString("email"),
-			viper.GetString("password"),
-			"America/New_York",
-		)
-		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
+		only, _ := cmd.Flags().GetStringSlice("only")
+		var sections []eightsleep.InfoSection
+		for _, name := range only {
+			section := eightsleep.InfoSection(strings.TrimSpace(name))
+			if !slices.Contains(eightsleep.InfoSections, section) {
+				return fmt.Errorf("unknown info section '%s' (must be one of: %s)", name, infoSectionNames())
+			}
+			sections = append(sections, section)
 		}
-		defer cli.Stop()
 
-		if err := cli.Start(cmd.Context()); err != nil {
-			return fmt.Errorf("failed to start client: %w", err)
-		}
-
-		if err := cli.TurnOn(cmd.Context()); err != nil {
+		cli, err := newClient(cmd.Context())
+		if err != nil {
 			return err
 		}
+		defer cli.Stop()
 
-		logger.Info("INFO")
-		if _, err := cli.Info(cmd.Context()); err != nil {
+		report, err := cli.Info(cmd.Context(), sections...)
+		if err != nil {
 			return fmt.Errorf("failed to get info: %w", err)
 		}
 
-		if err := cli.TurnOff(cmd.Context()); err != nil {
-			return err
+		if isStructuredOutput() {
+			return printOutput(report, nil)
+		}
+
+		for _, section := range []struct {
+			name  string
+			data  any
+			table func() *tableData
+		}{
+			{"TRENDS", report.Trends, func() *tableData { return sleepTable(report.Trends) }},
+			{"INTERVALS", report.Intervals, nil},
+			{"ROUTINES", report.Routines, func() *tableData { return alarmsTable(report.Routines) }},
+			{"HEALTH SURVEY TEST DRIVE", report.HealthSurvey, nil},
+			{"SUBSCRIPTIONS", report.Subscriptions, func() *tableData { return subscriptionsTable(report.Subscriptions) }},
+			{"AUTOPILOT DETAILS", report.Autopilot, func() *tableData { return autopilotTable(report.Autopilot, cli.Unit()) }},
+		} {
+			if reflect.ValueOf(section.data).IsNil() {
+				continue
+			}
+			logger.Info(section.name)
+			var tbl *tableData
+			if section.table != nil {
+				tbl = section.table()
+			}
+			if err := printOutput(section.data, tbl); err != nil {
+				return err
+			}
 		}
 
 		return nil
 	},
 }
 
+func infoSectionNames() string {
+	names := make([]string, 0, len(eightsleep.InfoSections))
+	for _, section := range eightsleep.InfoSections {
+		names = append(names, string(section))
+	}
+	return strings.Join(names, ", ")
+}
+
 func init() {
 	rootCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().StringSlice("only", nil, "Comma separated list of sections to include")
 }
@@ -0,0 +1,158 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+)
+
+// defaultLevelTolerance is how many heating levels a side may be off its expected level and
+// still match, when no tolerance is given
+const defaultLevelTolerance = 2
+
+// expectedState is a state a side of the pod is checked against, shared by `status --check`
+// and the daemon's state synchronization
+type expectedState struct {
+	Action      string // on, off, temp or ramp
+	Temperature string // for temp, in any format accepted by ParseTemperature
+	Tolerance   string // for temp, in the temperature's unit or app scale steps, optional
+	Ramp        *rampStep
+}
+
+// parseCheck parses a check such as "on", "off", "temp=68F" or "temp=68F±1"
+func parseCheck(check string) (expectedState, error) {
+	check = strings.TrimSpace(check)
+	action, value, _ := strings.Cut(check, "=")
+	switch strings.ToLower(action) {
+	case "on", "off":
+		if value != "" {
+			return expectedState{}, fmt.Errorf("check %q does not take a value", action)
+		}
+		return expectedState{Action: strings.ToLower(action)}, nil
+	case "temp":
+		if value == "" {
+			return expectedState{}, fmt.Errorf("temp check requires a temperature, e.g. temp=68F±1")
+		}
+		temp, tolerance, found := strings.Cut(value, "±")
+		if !found {
+			temp, tolerance, _ = strings.Cut(value, "+-")
+		}
+		state := expectedState{Action: "temp", Temperature: temp, Tolerance: tolerance}
+		if err := state.validate(); err != nil {
+			return expectedState{}, err
+		}
+		return state, nil
+	default:
+		return expectedState{}, fmt.Errorf("invalid check %q (expected on, off or temp=<temperature>[±<tolerance>])", check)
+	}
+}
+
+// scheduleExpectedState returns the state a schedule item leaves the pod in
+func scheduleExpectedState(item ScheduleItem) expectedState {
+	return expectedState{Action: item.Action, Temperature: item.Temperature, Ramp: item.ramp}
+}
+
+func (e expectedState) validate() error {
+	if err := validateTemperature(e.Temperature); err != nil {
+		return fmt.Errorf("invalid temperature '%s': %w", e.Temperature, err)
+	}
+	if e.Tolerance != "" {
+		if n, err := strconv.Atoi(e.Tolerance); err != nil || n < 0 {
+			return fmt.Errorf("invalid tolerance '%s' (must be a non-negative number)", e.Tolerance)
+		}
+	}
+	return nil
+}
+
+// matches reports whether a side of the pod is in the expected state. Temperatures are compared
+// against the side's target level, like the level the daemon set, so a side still ramping matches.
+func (e expectedState) matches(side *eightsleep.SideStatus, unit eightsleep.UnitOfTemperature) (bool, error) {
+	switch e.Action {
+	case "off":
+		return !side.On, nil
+
+	case "on":
+		return side.On, nil
+
+	case "temp":
+		if !side.On {
+			return false, nil
+		}
+		if e.Temperature == "" {
+			return false, fmt.Errorf("temperature action requires temperature value")
+		}
+
+		expected, err := eightsleep.ParseTemperature(e.Temperature, unit)
+		if err != nil {
+			return false, fmt.Errorf("invalid temperature: %w", err)
+		}
+
+		if e.Tolerance == "" {
+			return eightsleep.LevelsWithin(side.TargetLevel, expected, defaultLevelTolerance), nil
+		}
+		tolerance, err := strconv.Atoi(e.Tolerance)
+		if err != nil {
+			return false, fmt.Errorf("invalid tolerance: %w", err)
+		}
+		// the tolerance is in the temperature's own unit, or app scale steps
+		toleranceUnit := temperatureUnit(e.Temperature, unit)
+		low := eightsleep.AdjustLevel(expected, -tolerance, toleranceUnit)
+		high := eightsleep.AdjustLevel(expected, tolerance, toleranceUnit)
+		return side.TargetLevel >= low && side.TargetLevel <= high, nil
+
+	case "ramp":
+		if !side.On {
+			return false, nil
+		}
+		if e.Ramp == nil {
+			return false, fmt.Errorf("ramp action requires a step")
+		}
+
+		expected, err := e.Ramp.level(unit)
+		if err != nil {
+			return false, err
+		}
+		return eightsleep.LevelsWithin(side.TargetLevel, expected, defaultLevelTolerance), nil
+
+	default:
+		return false, fmt.Errorf("unknown action: %s", e.Action)
+	}
+}
+
+// temperatureUnit returns the unit a temperature is given in, or an empty unit for the app scale
+func temperatureUnit(temp string, defaultUnit eightsleep.UnitOfTemperature) eightsleep.UnitOfTemperature {
+	temp = strings.ToUpper(strings.TrimSpace(temp))
+	switch {
+	case strings.HasSuffix(temp, "F"):
+		return eightsleep.Fahrenheit
+	case strings.HasSuffix(temp, "C"):
+		return eightsleep.Celsius
+	}
+	if scale, err := strconv.Atoi(temp); err == nil && scale >= eightsleep.MIN_SCALE && scale <= eightsleep.MAX_SCALE {
+		return ""
+	}
+	return defaultUnit
+}
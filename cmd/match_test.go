@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+)
+
+func TestParseCheck(t *testing.T) {
+	tests := []struct {
+		check   string
+		want    expectedState
+		wantErr bool
+	}{
+		{check: "on", want: expectedState{Action: "on"}},
+		{check: " OFF ", want: expectedState{Action: "off"}},
+		{check: "temp=68F", want: expectedState{Action: "temp", Temperature: "68F"}},
+		{check: "temp=68F±1", want: expectedState{Action: "temp", Temperature: "68F", Tolerance: "1"}},
+		{check: "temp=20C+-2", want: expectedState{Action: "temp", Temperature: "20C", Tolerance: "2"}},
+		{check: "temp=-3", want: expectedState{Action: "temp", Temperature: "-3"}},
+		{check: "temp=-3±1", want: expectedState{Action: "temp", Temperature: "-3", Tolerance: "1"}},
+		{check: "on=1", wantErr: true},
+		{check: "temp", wantErr: true},
+		{check: "temp=", wantErr: true},
+		{check: "temp=hot", wantErr: true},
+		{check: "temp=68F±x", wantErr: true},
+		{check: "temp=68F±-1", wantErr: true},
+		{check: "warm", wantErr: true},
+		{check: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseCheck(tt.check)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseCheck(%q) error = %v, wantErr %v", tt.check, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (got.Action != tt.want.Action || got.Temperature != tt.want.Temperature || got.Tolerance != tt.want.Tolerance) {
+			t.Errorf("parseCheck(%q) = %+v, want %+v", tt.check, got, tt.want)
+		}
+	}
+}
+
+func TestExpectedStateMatches(t *testing.T) {
+	on := func(target int) *eightsleep.SideStatus {
+		return &eightsleep.SideStatus{On: true, TargetLevel: target}
+	}
+	off := &eightsleep.SideStatus{TargetLevel: -58}
+	tests := []struct {
+		name    string
+		state   expectedState
+		side    *eightsleep.SideStatus
+		unit    eightsleep.UnitOfTemperature
+		want    bool
+		wantErr bool
+	}{
+		{name: "on", state: expectedState{Action: "on"}, side: on(0), want: true},
+		{name: "on but off", state: expectedState{Action: "on"}, side: off},
+		{name: "off", state: expectedState{Action: "off"}, side: off, want: true},
+		{name: "off but on", state: expectedState{Action: "off"}, side: on(0)},
+		{name: "temp exact", state: expectedState{Action: "temp", Temperature: "68F"}, side: on(-58), want: true},
+		{name: "temp within default tolerance", state: expectedState{Action: "temp", Temperature: "68F"}, side: on(-56), want: true},
+		{name: "temp outside default tolerance", state: expectedState{Action: "temp", Temperature: "68F"}, side: on(-55)},
+		{name: "temp while off", state: expectedState{Action: "temp", Temperature: "68F"}, side: off},
+		{name: "temp in celsius", state: expectedState{Action: "temp", Temperature: "20C"}, side: on(-58), unit: eightsleep.Fahrenheit, want: true},
+		{name: "temp in the default unit", state: expectedState{Action: "temp", Temperature: "68"}, side: on(-58), unit: eightsleep.Fahrenheit, want: true},
+		{name: "temp in app scale", state: expectedState{Action: "temp", Temperature: "-3"}, side: on(-30), want: true},
+		{name: "temp tolerance in unit", state: expectedState{Action: "temp", Temperature: "68F", Tolerance: "1"}, side: on(-54), want: true}, // 69F
+		{name: "temp outside tolerance in unit", state: expectedState{Action: "temp", Temperature: "68F", Tolerance: "1"}, side: on(-49)},     // 70F
+		{name: "temp tolerance in app scale", state: expectedState{Action: "temp", Temperature: "-3", Tolerance: "1"}, side: on(-20), want: true},
+		{name: "temp outside tolerance in app scale", state: expectedState{Action: "temp", Temperature: "-3", Tolerance: "1"}, side: on(-10)},
+		{name: "temp without temperature", state: expectedState{Action: "temp"}, side: on(0), wantErr: true},
+		{name: "temp invalid", state: expectedState{Action: "temp", Temperature: "hot"}, side: on(0), wantErr: true},
+		{name: "ramp step", state: expectedState{Action: "ramp", Ramp: &rampStep{From: "-10", To: "0", Index: 1, Count: 2}}, side: on(-50), want: true},
+		{name: "ramp step missed", state: expectedState{Action: "ramp", Ramp: &rampStep{From: "-10", To: "0", Index: 1, Count: 2}}, side: on(-100)},
+		{name: "ramp without step", state: expectedState{Action: "ramp"}, side: on(0), wantErr: true},
+		{name: "unknown action", state: expectedState{Action: "warm"}, side: on(0), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := tt.state.matches(tt.side, tt.unit)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: matches() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
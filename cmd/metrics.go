@@ -0,0 +1,276 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+)
+
+// apiLatencyBuckets are the upper bounds in seconds of the API request latency histogram
+var apiLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// histogram is a Prometheus histogram with cumulative buckets
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (h *histogram) observe(v float64, buckets []float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(buckets))
+	}
+	for i, bound := range buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// apiRequestKey are the labels of an API request
+type apiRequestKey struct {
+	method, host, code string
+}
+
+// daemonMetrics are the daemon's Prometheus metrics, written in the text exposition format
+type daemonMetrics struct {
+	mu sync.Mutex
+
+	scheduled, executed, failed map[string]uint64 // by action
+	syncCorrections             uint64
+	tokenRefreshes              map[string]uint64 // by result
+	apiRequests                 map[apiRequestKey]*histogram
+	lastPoll                    time.Time
+	sides                       map[eightsleep.Side]eightsleep.SideStatus
+}
+
+var metrics = &daemonMetrics{
+	scheduled:      make(map[string]uint64),
+	executed:       make(map[string]uint64),
+	failed:         make(map[string]uint64),
+	tokenRefreshes: make(map[string]uint64),
+	apiRequests:    make(map[apiRequestKey]*histogram),
+	sides:          make(map[eightsleep.Side]eightsleep.SideStatus),
+}
+
+// hooks returns client hooks feeding the API request and token refresh metrics
+func (m *daemonMetrics) hooks() eightsleep.Hooks {
+	return eightsleep.Hooks{
+		Request: func(info eightsleep.RequestInfo) {
+			code := "error"
+			if info.StatusCode != 0 {
+				code = strconv.Itoa(info.StatusCode)
+			}
+			key := apiRequestKey{method: info.Method, host: info.Host, code: code}
+
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			h, ok := m.apiRequests[key]
+			if !ok {
+				h = &histogram{}
+				m.apiRequests[key] = h
+			}
+			h.observe(info.Duration.Seconds(), apiLatencyBuckets)
+		},
+		TokenRefresh: func(err error) {
+			result := "ok"
+			if err != nil {
+				result = "error"
+			}
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.tokenRefreshes[result]++
+		},
+	}
+}
+
+// actionScheduled counts an action that came due
+func (m *daemonMetrics) actionScheduled(action string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scheduled[action]++
+}
+
+// actionExecuted counts the outcome of a scheduled action
+func (m *daemonMetrics) actionExecuted(action string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.failed[action]++
+	} else {
+		m.executed[action]++
+	}
+}
+
+// syncCorrected counts a device state corrected by state synchronization
+func (m *daemonMetrics) syncCorrected() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.syncCorrections++
+}
+
+// polled records a successful status poll and the levels of both sides
+func (m *daemonMetrics) polled(status *eightsleep.PodStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastPoll = time.Now()
+	m.sides[eightsleep.Left] = status.Left
+	m.sides[eightsleep.Right] = status.Right
+}
+
+// writeTo writes the metrics in the Prometheus text exposition format
+func (m *daemonMetrics) writeTo(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeCounterByLabel(w, "clim8_actions_scheduled_total", "Scheduled actions that came due.", "action", m.scheduled)
+	writeCounterByLabel(w, "clim8_actions_executed_total", "Scheduled actions executed successfully.", "action", m.executed)
+	writeCounterByLabel(w, "clim8_actions_failed_total", "Scheduled actions that failed.", "action", m.failed)
+	writeCounterByLabel(w, "clim8_token_refreshes_total", "Logins to the Eight Sleep API by result.", "result", m.tokenRefreshes)
+
+	writeHeader(w, "clim8_sync_state_corrections_total", "Device states corrected by state synchronization.", "counter")
+	fmt.Fprintf(w, "clim8_sync_state_corrections_total %d\n", m.syncCorrections)
+
+	writeHeader(w, "clim8_api_request_duration_seconds", "Eight Sleep API request latency by method, host and status code.", "histogram")
+	keys := make([]apiRequestKey, 0, len(m.apiRequests))
+	for key := range m.apiRequests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.host != b.host {
+			return a.host < b.host
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.code < b.code
+	})
+	for _, key := range keys {
+		h := m.apiRequests[key]
+		labels := fmt.Sprintf(`method=%q,host=%q,code=%q`, key.method, key.host, key.code)
+		for i, bound := range apiLatencyBuckets {
+			fmt.Fprintf(w, "clim8_api_request_duration_seconds_bucket{%s,le=%q} %d\n", labels, formatFloat(bound), h.counts[i])
+		}
+		fmt.Fprintf(w, "clim8_api_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(w, "clim8_api_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(h.sum))
+		fmt.Fprintf(w, "clim8_api_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+
+	writeHeader(w, "clim8_last_successful_poll_timestamp_seconds", "Unix time of the last successful device status poll.", "gauge")
+	if !m.lastPoll.IsZero() {
+		fmt.Fprintf(w, "clim8_last_successful_poll_timestamp_seconds %s\n", formatFloat(float64(m.lastPoll.UnixMilli())/1000))
+	}
+
+	sides := []eightsleep.Side{eightsleep.Left, eightsleep.Right}
+	writeSideGauge(w, "clim8_side_on", "Whether the side is on (1) or off (0).", m.sides, sides, func(s eightsleep.SideStatus) int {
+		if s.On {
+			return 1
+		}
+		return 0
+	})
+	writeSideGauge(w, "clim8_side_level", "Current heating level of the side (-100..100).", m.sides, sides, func(s eightsleep.SideStatus) int { return s.Level })
+	writeSideGauge(w, "clim8_side_target_level", "Target heating level of the side (-100..100).", m.sides, sides, func(s eightsleep.SideStatus) int { return s.TargetLevel })
+}
+
+func writeHeader(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeCounterByLabel(w io.Writer, name, help, label string, values map[string]uint64) {
+	writeHeader(w, name, help, "counter")
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s{%s=%q} %d\n", name, label, key, values[key])
+	}
+}
+
+func writeSideGauge(w io.Writer, name, help string, values map[eightsleep.Side]eightsleep.SideStatus, sides []eightsleep.Side, value func(eightsleep.SideStatus) int) {
+	writeHeader(w, name, help, "gauge")
+	for _, side := range sides {
+		if status, ok := values[side]; ok {
+			fmt.Fprintf(w, "%s{side=%q} %d\n", name, strings.ToLower(string(side)), value(status))
+		}
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// serveMetrics writes the daemon's metrics for Prometheus to scrape
+func serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.writeTo(w)
+}
+
+// serveMetricsListener serves /metrics on daemon.metrics-listen when set, the returned function
+// shuts it down
+func serveMetricsListener(ctx context.Context) (func(), error) {
+	address := currentDaemonSettings().config.GetString("daemon.metrics-listen")
+	if address == "" {
+		return func() {}, nil
+	}
+
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", serveMetrics)
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server stopped", "err", err)
+		}
+	}()
+	logger.Info("Serving metrics", "address", "http://"+ln.Addr().String()+"/metrics")
+
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}, nil
+}
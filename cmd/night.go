@@ -0,0 +1,66 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"time"
+)
+
+// defaultDayBoundary is when one night of the schedule ends and the next begins
+const defaultDayBoundary = "12:00"
+
+// dayBoundary returns the configured day boundary
+func dayBoundary() string {
+	return currentDaemonSettings().dayBoundary
+}
+
+// nightStart returns when the night containing t started. A night runs from the day boundary on
+// one day to the day boundary on the next, so items after midnight belong to the previous
+// evening's night.
+func nightStart(t time.Time) time.Time {
+	boundary := dayBoundary()
+	if _, err := parseTime(boundary, t); err != nil {
+		boundary = defaultDayBoundary
+	}
+	start, _ := parseTime(boundary, t)
+	if start.After(t) {
+		start, _ = parseTime(boundary, t.AddDate(0, 0, -1))
+	}
+	return start
+}
+
+// nightOf returns the date of the night t belongs to, the evening the night starts on
+func nightOf(t time.Time) string {
+	return nightStart(t).Format("2006-01-02")
+}
+
+// nightTime returns when the item is due in the night that started at start
+func (item ScheduleItem) nightTime(start time.Time) (time.Time, error) {
+	t, err := parseTime(item.Time, start)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t.Before(start) {
+		return parseTime(item.Time, start.AddDate(0, 0, 1))
+	}
+	return t, nil
+}
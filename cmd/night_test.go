@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+// withDayBoundary runs the test with the given day boundary
+func withDayBoundary(t *testing.T, boundary string) {
+	settings := *defaultDaemonSettings
+	settings.dayBoundary = boundary
+	loadedDaemonSettings.Store(&settings)
+	t.Cleanup(func() { loadedDaemonSettings.Store(nil) })
+}
+
+func TestNightStart(t *testing.T) {
+	tests := []struct {
+		name     string
+		boundary string
+		now      time.Time
+		want     time.Time
+	}{
+		{name: "evening", now: date(1, 22, 0), want: date(1, 12, 0)},
+		{name: "after midnight", now: date(2, 1, 0), want: date(1, 12, 0)},
+		{name: "morning", now: date(2, 11, 59), want: date(1, 12, 0)},
+		{name: "at the boundary", now: date(2, 12, 0), want: date(2, 12, 0)},
+		{name: "custom boundary", boundary: "15:00", now: date(2, 14, 0), want: date(1, 15, 0)},
+		{name: "invalid boundary", boundary: "noon", now: date(2, 1, 0), want: date(1, 12, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.boundary != "" {
+				withDayBoundary(t, tt.boundary)
+			}
+			if got := nightStart(tt.now); !got.Equal(tt.want) {
+				t.Errorf("nightStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNightTime(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		name  string
+		item  ScheduleItem
+		start time.Time
+		want  time.Time
+	}{
+		{name: "evening", item: ScheduleItem{Time: "22:00"}, start: date(1, 12, 0), want: date(1, 22, 0)},
+		{name: "after midnight", item: ScheduleItem{Time: "02:00"}, start: date(1, 12, 0), want: date(2, 2, 0)},
+		{name: "at the boundary", item: ScheduleItem{Time: "12:00"}, start: date(1, 12, 0), want: date(1, 12, 0)},
+		{name: "before the boundary", item: ScheduleItem{Time: "11:59"}, start: date(1, 12, 0), want: date(2, 11, 59)},
+		{
+			name:  "spring forward",
+			item:  ScheduleItem{Time: "02:30"},
+			start: time.Date(2024, 3, 9, 12, 0, 0, 0, la),
+			want:  time.Date(2024, 3, 10, 3, 30, 0, 0, la),
+		},
+		{
+			name:  "fall back",
+			item:  ScheduleItem{Time: "06:00"},
+			start: time.Date(2024, 11, 2, 12, 0, 0, 0, la),
+			want:  time.Date(2024, 11, 3, 6, 0, 0, 0, la),
+		},
+	}
+	for _, tt := range tests {
+		got, err := tt.item.nightTime(tt.start)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("%s: nightTime() = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestGetExpectedState(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skip(err)
+	}
+	schedule := []ScheduleItem{
+		{Time: "22:00", Action: "on"},
+		{Time: "02:00", Action: "temp"},
+		{Time: "06:00", Action: "off"},
+	}
+	friday := []ScheduleItem{
+		{Time: "22:00", Action: "on", Days: []string{"fri"}},
+		{Time: "02:00", Action: "temp", Days: []string{"fri"}},
+		{Time: "07:00", Action: "off", Days: []string{"fri"}},
+	}
+	tests := []struct {
+		name     string
+		schedule []ScheduleItem
+		now      time.Time
+		want     string // the expected item's time, empty for none
+	}{
+		{name: "before the first item", schedule: schedule, now: date(1, 21, 0)},
+		{name: "evening", schedule: schedule, now: date(1, 23, 0), want: "22:00"},
+		{name: "across midnight", schedule: schedule, now: date(2, 0, 30), want: "22:00"},
+		{name: "after midnight", schedule: schedule, now: date(2, 3, 0), want: "02:00"},
+		{name: "morning", schedule: schedule, now: date(2, 11, 0), want: "06:00"},
+		{name: "next night", schedule: schedule, now: date(2, 13, 0)},
+		{name: "days select the night", schedule: friday, now: date(2, 3, 0), want: "02:00"},
+		{name: "night ended", schedule: friday, now: date(2, 8, 0), want: "07:00"},
+		{name: "other night", schedule: friday, now: date(1, 3, 0)},
+		{name: "spring forward", schedule: schedule, now: time.Date(2024, 3, 10, 3, 15, 0, 0, la), want: "02:00"},
+		{name: "fall back before the repeated hour", schedule: schedule, now: time.Date(2024, 11, 3, 0, 30, 0, 0, la), want: "22:00"},
+		{name: "fall back after the repeated hour", schedule: schedule, now: time.Date(2024, 11, 3, 2, 30, 0, 0, la), want: "02:00"},
+		{name: "empty schedule", now: date(1, 23, 0)},
+	}
+	for _, tt := range tests {
+		got, err := getExpectedState(tt.schedule, tt.now)
+		if err != nil {
+			t.Errorf("%s: getExpectedState() error = %v", tt.name, err)
+			continue
+		}
+		if (got == nil) != (tt.want == "") || (got != nil && got.Time != tt.want) {
+			t.Errorf("%s: getExpectedState() = %+v, want %q", tt.name, got, tt.want)
+		}
+	}
+}
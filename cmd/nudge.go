@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// warmerCmd represents the warmer command
+var warmerCmd = &cobra.Command{
+	Use:   "warmer [amount]",
+	Short: "Make Eight Sleep Pod warmer",
+	Long: `Make Eight Sleep Pod warmer relative to its current level.
+
+The amount defaults to 1 step on the app's -10..+10 scale and can include
+a unit (F for Fahrenheit or C for Celsius) to adjust by degrees instead.
+If the pod is off it is turned on at the adjusted level, unless the level is
+already at its limit.`,
+	Example: "  clim8 warmer\n  clim8 warmer 2\n  clim8 warmer 2F",
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return nudgeTemperature(cmd, args, "+")
+	},
+}
+
+// coolerCmd represents the cooler command
+var coolerCmd = &cobra.Command{
+	Use:   "cooler [amount]",
+	Short: "Make Eight Sleep Pod cooler",
+	Long: `Make Eight Sleep Pod cooler relative to its current level.
+
+The amount defaults to 1 step on the app's -10..+10 scale and can include
+a unit (F for Fahrenheit or C for Celsius) to adjust by degrees instead.
+If the pod is off it is turned on at the adjusted level, unless the level is
+already at its limit.`,
+	Example: "  clim8 cooler\n  clim8 cooler 2\n  clim8 cooler 1C",
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return nudgeTemperature(cmd, args, "-")
+	},
+}
+
+func nudgeTemperature(cmd *cobra.Command, args []string, sign string) error {
+	amount := "1"
+	if len(args) == 1 {
+		amount = strings.TrimLeft(args[0], "+-")
+	}
+	return adjustTemperature(cmd, sign+amount)
+}
+
+// adjustTemperature applies a relative adjustment to the current level and reports old → new
+func adjustTemperature(cmd *cobra.Command, delta string) error {
+	if viper.GetBool("verbose") {
+		logger.SetLevel(log.DebugLevel)
+	}
+
+	if _, _, err := eightsleep.ParseTemperatureDelta(delta); err != nil {
+		return err
+	}
+
+	cli, err := newClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer cli.Stop()
+
+	adj, err := cli.AdjustTemperature(cmd.Context(), delta)
+	if err != nil {
+		return err
+	}
+	if !adj.Changed() {
+		logger.Warn("Temperature already at limit, nothing changed", "level", eightsleep.FormatLevel(adj.To, cli.Unit()))
+		if adj.WasOff {
+			logger.Warn("Pod is OFF, left it off")
+		}
+		return nil
+	}
+	if adj.WasOff {
+		logger.Info("Pod was OFF, turned it ON")
+	}
+	logger.Info(fmt.Sprintf("Temperature Adjusted: %s → %s", eightsleep.FormatLevel(adj.From, cli.Unit()), eightsleep.FormatLevel(adj.To, cli.Unit())))
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(warmerCmd)
+	rootCmd.AddCommand(coolerCmd)
+}
@@ -22,9 +22,6 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"fmt"
-
-	"github.com/blacktop/clim8/pkg/eightsleep"
 	"github.com/charmbracelet/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -40,20 +37,12 @@ var offCmd = &cobra.Command{
 			logger.SetLevel(log.DebugLevel)
 		}
 
-		cli, err := eightsleep.NewClient(
-			viper.GetString("email"),
-			viper.GetString("password"),
-			"America/New_York",
-		)
+		cli, err := newClient(cmd.Context())
 		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
+			return err
 		}
 		defer cli.Stop()
 
-		if err := cli.Start(cmd.Context()); err != nil {
-			return fmt.Errorf("failed to start client: %w", err)
-		}
-
 		if err := cli.TurnOff(cmd.Context()); err != nil {
 			return err
 		}
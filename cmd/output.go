@@ -0,0 +1,217 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/quick"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputTable = "table"
+	outputPlain = "plain"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormats = []string{outputTable, outputPlain, outputJSON, outputYAML}
+
+// tableData is the human-friendly layout of a command's output
+type tableData struct {
+	Headers []string
+	Rows    [][]string
+}
+
+func (t *tableData) add(row ...string) {
+	t.Rows = append(t.Rows, row)
+}
+
+// outputFormat returns the format selected with --output
+func outputFormat() (string, error) {
+	format := strings.ToLower(viper.GetString("output"))
+	for _, f := range outputFormats {
+		if format == f {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("invalid output format '%s' (must be one of: %s)", format, strings.Join(outputFormats, ", "))
+}
+
+// isStructuredOutput reports whether the selected format is meant for machines (json or yaml).
+// --output is validated by the root command before any command runs.
+func isStructuredOutput() bool {
+	format, _ := outputFormat()
+	return format == outputJSON || format == outputYAML
+}
+
+// useColor reports whether output to stdout should be colorized
+func useColor() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	format, _ := outputFormat()
+	if format == outputPlain {
+		return false
+	}
+	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+}
+
+// printOutput writes v to stdout in the format selected with --output.
+// For table and plain output tbl is used as the layout; when nil, v is flattened into key/value rows.
+func printOutput(v any, tbl *tableData) error {
+	format, err := outputFormat()
+	if err != nil {
+		return err
+	}
+	return writeOutput(os.Stdout, format, v, tbl)
+}
+
+func writeOutput(w io.Writer, format string, v any, tbl *tableData) error {
+	switch format {
+	case outputJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal json: %v", err)
+		}
+		return highlight(w, string(data)+"\n", "json")
+	case outputYAML:
+		// round-trip through JSON so the field names match the json output
+		var generic any
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal json: %v", err)
+		}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return fmt.Errorf("failed to unmarshal json: %v", err)
+		}
+		var buf strings.Builder
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(generic); err != nil {
+			return fmt.Errorf("failed to marshal yaml: %v", err)
+		}
+		return highlight(w, buf.String(), "yaml")
+	}
+
+	if tbl == nil {
+		flat, err := flatten(v)
+		if err != nil {
+			return err
+		}
+		tbl = flat
+	}
+
+	if format == outputPlain {
+		for _, row := range tbl.Rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return nil
+	}
+
+	t := table.New().
+		Headers(tbl.Headers...).
+		Rows(tbl.Rows...)
+	if useColor() {
+		t.BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("240"))).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				if row == table.HeaderRow {
+					return lipgloss.NewStyle().Bold(true).Padding(0, 1)
+				}
+				return lipgloss.NewStyle().Padding(0, 1)
+			})
+	} else {
+		t.Border(lipgloss.ASCIIBorder()).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				return lipgloss.NewStyle().Padding(0, 1)
+			})
+	}
+	fmt.Fprintln(w, t.Render())
+	return nil
+}
+
+func highlight(w io.Writer, data, lexer string) error {
+	if !useColor() {
+		_, err := io.WriteString(w, data)
+		return err
+	}
+	if err := quick.Highlight(w, data, lexer, "terminal256", "nord"); err != nil {
+		return fmt.Errorf("failed to highlight %s: %v", lexer, err)
+	}
+	return nil
+}
+
+// flatten converts v into key/value rows using dotted paths for nested fields
+func flatten(v any) (*tableData, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal json: %v", err)
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal json: %v", err)
+	}
+	tbl := &tableData{Headers: []string{"Key", "Value"}}
+	flattenInto(tbl, "", generic)
+	return tbl, nil
+}
+
+func flattenInto(tbl *tableData, prefix string, v any) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			flattenInto(tbl, join(k), val[k])
+		}
+	case []any:
+		if len(val) == 0 {
+			tbl.add(prefix, "[]")
+		}
+		for i, item := range val {
+			flattenInto(tbl, fmt.Sprintf("%s[%d]", prefix, i), item)
+		}
+	case nil:
+		tbl.add(prefix, "")
+	default:
+		tbl.add(prefix, fmt.Sprint(val))
+	}
+}
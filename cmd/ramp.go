@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+)
+
+// rampFile returns the path ramp rates are persisted to between runs
+func rampFile() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ramp.json"), nil
+}
+
+// loadRampRates reads the ramp rates learned by previous runs. A missing or unreadable file
+// starts over with no observations.
+func loadRampRates() *eightsleep.RampRates {
+	ramps := eightsleep.NewRampRates()
+	path, err := rampFile()
+	if err != nil {
+		return ramps
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Debug("Failed to read ramp rates", "err", err)
+		}
+		return ramps
+	}
+	if err := json.Unmarshal(data, ramps); err != nil {
+		logger.Debug("Failed to parse ramp rates, starting over", "err", err)
+		return eightsleep.NewRampRates()
+	}
+	return ramps
+}
+
+// saveRampRates persists the client's ramp rates if Status observed anything new
+func saveRampRates(cli *eightsleep.Client) {
+	ramps := cli.RampRates()
+	if ramps == nil || !ramps.Dirty() {
+		return
+	}
+	if err := writeRampRates(ramps); err != nil {
+		logger.Debug("Failed to save ramp rates", "err", err)
+		return
+	}
+	ramps.MarkClean()
+}
+
+// writeRampRates replaces the ramp rates file atomically. The daemon and the status commands may
+// save at the same time, so each write goes through its own temporary file.
+func writeRampRates(ramps *eightsleep.RampRates) error {
+	path, err := rampFile()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ramps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ramp rates: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "ramp-*.json.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write ramp rates: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write ramp rates: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write ramp rates: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write ramp rates: %w", err)
+	}
+	return nil
+}
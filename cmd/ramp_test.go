@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+)
+
+func TestWriteRampRates(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	ramps := eightsleep.NewRampRates()
+	ramps.Rates["pod/heating"] = eightsleep.RampRate{LevelsPerMinute: 2.5, Samples: 3}
+	for range 2 {
+		if err := writeRampRates(ramps); err != nil {
+			t.Fatalf("writeRampRates() error = %v", err)
+		}
+	}
+
+	got := loadRampRates()
+	if rate, ok := got.Rate("pod", eightsleep.Heating); !ok || rate.LevelsPerMinute != 2.5 || rate.Samples != 3 {
+		t.Errorf("loadRampRates() rate = %+v, %v", rate, ok)
+	}
+
+	// only the rates file is left behind
+	entries, err := os.ReadDir(filepath.Join(home, ".config", "clim8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "ramp.json" {
+		t.Errorf("config dir has %v, want only ramp.json", entries)
+	}
+	info, err := entries[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("ramp.json mode = %v, want 0600", info.Mode().Perm())
+	}
+}
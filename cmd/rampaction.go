@@ -0,0 +1,134 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+)
+
+// defaultRampStep is how often a ramp action changes the temperature when no step is given
+const defaultRampStep = 5 * time.Minute
+
+// rampStep is one temperature change of a ramp action
+type rampStep struct {
+	From, To     string
+	Index, Count int
+}
+
+// level returns the heating level of the step, interpolated between the ramp's from and to levels
+func (s rampStep) level(unit eightsleep.UnitOfTemperature) (int, error) {
+	from, err := eightsleep.ParseTemperature(s.From, unit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ramp from temperature: %w", err)
+	}
+	to, err := eightsleep.ParseTemperature(s.To, unit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ramp to temperature: %w", err)
+	}
+	if s.Count == 0 {
+		return to, nil
+	}
+	return int(math.Round(float64(from) + float64(to-from)*float64(s.Index)/float64(s.Count))), nil
+}
+
+// rampTiming returns the duration of a ramp action and the interval between its steps
+func (item ScheduleItem) rampTiming() (time.Duration, time.Duration, error) {
+	duration, err := time.ParseDuration(item.Duration)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid duration '%s': %w", item.Duration, err)
+	}
+	if duration < time.Minute {
+		return 0, 0, fmt.Errorf("duration must be at least 1m")
+	}
+	step := defaultRampStep
+	if item.Step != "" {
+		if step, err = time.ParseDuration(item.Step); err != nil {
+			return 0, 0, fmt.Errorf("invalid step '%s': %w", item.Step, err)
+		}
+	}
+	if step < time.Minute || step > duration {
+		return 0, 0, fmt.Errorf("step must be between 1m and the duration")
+	}
+	return duration, step, nil
+}
+
+// rampStart returns the latest start of the ramp at or before now, and whether the ramp runs on
+// the night of that start. Ramps may run past midnight, so the start can be on the previous day.
+func rampStart(item ScheduleItem, now time.Time) (time.Time, bool) {
+	start, err := parseTime(item.Time, now)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if start.After(now) {
+		start, _ = parseTime(item.Time, now.AddDate(0, 0, -1))
+	}
+	return start, item.runsOn(nightStart(start).Weekday())
+}
+
+// rampStepAt returns the step of a ramp started at start that applies at t. Once the ramp is over
+// its last step, the to temperature, applies.
+func (item ScheduleItem) rampStepAt(start, t time.Time) (rampStep, error) {
+	duration, step, err := item.rampTiming()
+	if err != nil {
+		return rampStep{}, err
+	}
+	count := int((duration + step - 1) / step)
+	index := count
+	if elapsed := t.Sub(start); elapsed < duration {
+		index = min(int(elapsed/step), count-1)
+	}
+	return rampStep{From: item.From, To: item.To, Index: index, Count: count}, nil
+}
+
+// rampEnd returns when a ramp started at start finishes
+func (item ScheduleItem) rampEnd(start time.Time) time.Time {
+	duration, _, err := item.rampTiming()
+	if err != nil {
+		return start
+	}
+	return start.Add(duration)
+}
+
+// validateRamp checks the fields of a ramp action, unitless temperatures are checked against the
+// configured unit
+func validateRamp(item ScheduleItem, unit string) error {
+	if item.From == "" || item.To == "" {
+		return fmt.Errorf("from and to temperatures required for ramp action")
+	}
+	if err := validateTemperatureUnit(item.From, unit); err != nil {
+		return fmt.Errorf("invalid from temperature '%s': %w", item.From, err)
+	}
+	if err := validateTemperatureUnit(item.To, unit); err != nil {
+		return fmt.Errorf("invalid to temperature '%s': %w", item.To, err)
+	}
+	if item.Duration == "" {
+		return fmt.Errorf("duration required for ramp action")
+	}
+	if _, _, err := item.rampTiming(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRampStepAt(t *testing.T) {
+	start := time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		item      ScheduleItem
+		after     time.Duration
+		wantIndex int
+		wantCount int
+		wantErr   bool
+	}{
+		{name: "start", item: ScheduleItem{Duration: "30m", Step: "10m"}, after: 0, wantIndex: 0, wantCount: 3},
+		{name: "mid step", item: ScheduleItem{Duration: "30m", Step: "10m"}, after: 15 * time.Minute, wantIndex: 1, wantCount: 3},
+		{name: "last step", item: ScheduleItem{Duration: "30m", Step: "10m"}, after: 29 * time.Minute, wantIndex: 2, wantCount: 3},
+		{name: "finished", item: ScheduleItem{Duration: "30m", Step: "10m"}, after: 30 * time.Minute, wantIndex: 3, wantCount: 3},
+		{name: "long after", item: ScheduleItem{Duration: "30m", Step: "10m"}, after: 5 * time.Hour, wantIndex: 3, wantCount: 3},
+		{name: "default step", item: ScheduleItem{Duration: "1h"}, after: 12 * time.Minute, wantIndex: 2, wantCount: 12},
+		{name: "uneven step", item: ScheduleItem{Duration: "25m", Step: "10m"}, after: 24 * time.Minute, wantIndex: 2, wantCount: 3},
+		{name: "before start", item: ScheduleItem{Duration: "30m", Step: "10m"}, after: -time.Minute, wantIndex: 0, wantCount: 3},
+		{name: "invalid duration", item: ScheduleItem{Duration: "soon"}, wantErr: true},
+		{name: "too short", item: ScheduleItem{Duration: "30s"}, wantErr: true},
+		{name: "step longer than duration", item: ScheduleItem{Duration: "10m", Step: "20m"}, wantErr: true},
+		{name: "step too short", item: ScheduleItem{Duration: "10m", Step: "30s"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt.item.From, tt.item.To = "-5", "5"
+		got, err := tt.item.rampStepAt(start, start.Add(tt.after))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: rampStepAt() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (got.Index != tt.wantIndex || got.Count != tt.wantCount || got.From != "-5" || got.To != "5") {
+			t.Errorf("%s: rampStepAt() = %+v, want index %d of %d", tt.name, got, tt.wantIndex, tt.wantCount)
+		}
+	}
+}
+
+func TestRampStepLevel(t *testing.T) {
+	tests := []struct {
+		step    rampStep
+		want    int
+		wantErr bool
+	}{
+		{step: rampStep{From: "-5", To: "5", Index: 0, Count: 4}, want: -50},
+		{step: rampStep{From: "-5", To: "5", Index: 1, Count: 4}, want: -25},
+		{step: rampStep{From: "-5", To: "5", Index: 4, Count: 4}, want: 50},
+		{step: rampStep{From: "-5", To: "5", Index: 1, Count: 3}, want: -17},
+		{step: rampStep{From: "-5", To: "5"}, want: 50},
+		{step: rampStep{From: "hot", To: "5", Count: 1}, wantErr: true},
+		{step: rampStep{From: "-5", To: "cold", Count: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := tt.step.level("")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%+v.level() error = %v, wantErr %v", tt.step, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("%+v.level() = %d, want %d", tt.step, got, tt.want)
+		}
+	}
+}
@@ -22,11 +22,17 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/blacktop/clim8/pkg/eightsleep"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/charmbracelet/lipgloss"
@@ -59,16 +65,34 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("verbose", "V", false, "Enable verbose debug logging")
 	rootCmd.PersistentFlags().StringP("email", "e", "", "Email address")
 	rootCmd.PersistentFlags().StringP("password", "p", "", "Password")
+	rootCmd.PersistentFlags().String("timezone", "", "Timezone (defaults to the pod's timezone)")
+	rootCmd.PersistentFlags().String("unit", "", "Temperature unit F or C (defaults to the account's measurement system)")
+	rootCmd.PersistentFlags().StringP("output", "o", outputTable, "Output format (table, plain, json, yaml)")
 	rootCmd.PersistentFlags().Bool("config-quiet", false, "silence config file loading message")
 	rootCmd.PersistentFlags().MarkHidden("config-quiet")
-	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
-	viper.BindPFlag("email", rootCmd.PersistentFlags().Lookup("email"))
-	viper.BindPFlag("password", rootCmd.PersistentFlags().Lookup("password"))
-	viper.BindPFlag("config-quiet", rootCmd.PersistentFlags().Lookup("config-quiet"))
+	bindRootFlags(viper.GetViper())
 	// Settings
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
 }
 
+// bindRootFlags binds the global flags to their config keys in v
+func bindRootFlags(v *viper.Viper) {
+	v.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	v.BindPFlag("email", rootCmd.PersistentFlags().Lookup("email"))
+	v.BindPFlag("password", rootCmd.PersistentFlags().Lookup("password"))
+	v.BindPFlag("timezone", rootCmd.PersistentFlags().Lookup("timezone"))
+	v.BindPFlag("unit", rootCmd.PersistentFlags().Lookup("unit"))
+	v.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
+	v.BindPFlag("config-quiet", rootCmd.PersistentFlags().Lookup("config-quiet"))
+}
+
+// configureEnv makes v read CLIM8_* environment variables, e.g. CLIM8_DAEMON_CATCH_UP
+func configureEnv(v *viper.Viper) {
+	v.SetEnvPrefix("clim8")
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	v.AutomaticEnv()
+}
+
 func initConfig() {
 	// Find home directory.
 	home, err := os.UserHomeDir()
@@ -79,9 +103,7 @@ func initConfig() {
 	viper.SetConfigType("yaml")
 	viper.SetConfigName("config")
 
-	viper.SetEnvPrefix("clim8")
-	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
-	viper.AutomaticEnv()
+	configureEnv(viper.GetViper())
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
@@ -91,17 +113,142 @@ func initConfig() {
 	}
 }
 
+// configDir returns the clim8 config directory (~/.config/clim8), creating it if needed
+func configDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	dir := filepath.Join(home, ".config", "clim8")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return dir, nil
+}
+
+// requireCapability refuses commands the pod cannot do, e.g. audio on a pod without speakers
+func requireCapability(cli *eightsleep.Client, capability eightsleep.Capability) error {
+	caps := cli.Capabilities()
+	if caps.Has(capability) || capability == eightsleep.CapBase && caps.HasBase() {
+		return nil
+	}
+	return fmt.Errorf("this pod does not support %s (see 'clim8 feats')", capability)
+}
+
+// clientHooks observe every client created by newClient
+var clientHooks eightsleep.Hooks
+
+// newClient creates an Eight Sleep client from the config and logs in
+func newClient(ctx context.Context) (*eightsleep.Client, error) {
+	return newClientFrom(ctx, viper.GetViper())
+}
+
+// newClientFrom creates an Eight Sleep client from the config in v and logs in
+func newClientFrom(ctx context.Context, v *viper.Viper) (*eightsleep.Client, error) {
+	cli, err := eightsleep.NewClient(
+		v.GetString("email"),
+		v.GetString("password"),
+		v.GetString("timezone"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	if u := v.GetString("unit"); u != "" {
+		unit, err := eightsleep.ParseUnit(u)
+		if err != nil {
+			return nil, err
+		}
+		cli.SetUnit(unit)
+	}
+	cli.SetRampRates(loadRampRates())
+	cli.SetHooks(clientHooks)
+	if err := cli.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start client: %w", err)
+	}
+	return cli, nil
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "clim8",
 	Short: "Eight Sleep CLI",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// validate --output once so the output helpers can rely on it
+		_, err := outputFormat()
+		return err
+	},
 }
 
+// exitError is returned by commands that need to exit with a specific code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	rootCmd.SetArgs(moveNegativeArgs(os.Args[1:]))
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			if exitErr.err != nil {
+				log.Error(exitErr.err.Error())
+			}
+			os.Exit(exitErr.code)
+		}
 		log.Error(err.Error())
 		os.Exit(1)
 	}
 }
+
+// negativeArgsAnnotation marks commands whose arguments may be negative numbers, e.g. `temp -3`
+const negativeArgsAnnotation = "negative-args"
+
+// moveNegativeArgs moves negative numbers given as arguments to an annotated command after a
+// "--", so they are not parsed as shorthand flags. Values of flags, e.g. `--level -30`, stay.
+func moveNegativeArgs(args []string) []string {
+	cmd, _, err := rootCmd.Find(args)
+	if err != nil || cmd.Annotations[negativeArgsAnnotation] == "" || slices.Contains(args, "--") {
+		return args
+	}
+	var flags, negative []string
+	for i, arg := range args {
+		if len(arg) > 1 && arg[0] == '-' && arg[1] >= '0' && arg[1] <= '9' && (i == 0 || !flagTakesValue(cmd, args[i-1])) {
+			negative = append(negative, arg)
+			continue
+		}
+		flags = append(flags, arg)
+	}
+	if len(negative) == 0 {
+		return args
+	}
+	return append(append(flags, "--"), negative...)
+}
+
+// flagTakesValue reports whether arg is a flag of cmd that reads its value from the next argument
+func flagTakesValue(cmd *cobra.Command, arg string) bool {
+	if !strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+		return false
+	}
+	var flag *pflag.Flag
+	if name, ok := strings.CutPrefix(arg, "--"); ok {
+		if flag = cmd.Flags().Lookup(name); flag == nil {
+			flag = cmd.InheritedFlags().Lookup(name)
+		}
+	} else if len(arg) == 2 {
+		if flag = cmd.Flags().ShorthandLookup(arg[1:]); flag == nil {
+			flag = cmd.InheritedFlags().ShorthandLookup(arg[1:])
+		}
+	}
+	return flag != nil && flag.NoOptDefVal == ""
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+)
+
+// sdNotify sends a state such as "READY=1" to the service manager. It does nothing unless
+// running as a systemd service with Type=notify, which sets NOTIFY_SOCKET.
+func sdNotify(state ...string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	// an abstract socket
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(strings.Join(state, "\n"))); err != nil {
+		return fmt.Errorf("failed to notify service manager: %w", err)
+	}
+	return nil
+}
+
+// notifyService sends a state to the service manager, logging failures
+func notifyService(state ...string) {
+	if err := sdNotify(state...); err != nil {
+		logger.Debug("Failed to notify systemd", "err", err)
+	}
+}
+
+// watchdogInterval returns how often systemd expects a WATCHDOG=1 keep-alive, or zero when the
+// watchdog is disabled or meant for another process
+func watchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// watchdogKey marks the context of the scheduler loop, whose requests keep the watchdog alive
+type watchdogKey struct{}
+
+// withWatchdog marks ctx as the scheduler loop's, see watchdogHooks
+func withWatchdog(ctx context.Context) context.Context {
+	return context.WithValue(ctx, watchdogKey{}, true)
+}
+
+// watchdogHooks extends hooks to send a watchdog keep-alive after every API request the scheduler
+// loop makes, so a wake making several requests is not mistaken for a hang as long as each
+// finishes within its timeout. Requests made elsewhere, e.g. by control API handlers, do not
+// count, so they cannot keep a hung scheduler alive.
+func watchdogHooks(hooks eightsleep.Hooks) eightsleep.Hooks {
+	if watchdogInterval() == 0 {
+		return hooks
+	}
+	request := hooks.Request
+	hooks.Request = func(info eightsleep.RequestInfo) {
+		if request != nil {
+			request(info)
+		}
+		if info.Context != nil && info.Context.Value(watchdogKey{}) != nil {
+			notifyService("WATCHDOG=1")
+		}
+	}
+	return hooks
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+)
+
+func TestWatchdogHooks(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", socket)
+	t.Setenv("WATCHDOG_USEC", "300000000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	requests := 0
+	hooks := watchdogHooks(eightsleep.Hooks{Request: func(eightsleep.RequestInfo) { requests++ }})
+
+	// a control API handler polling the pod must not keep the watchdog alive
+	hooks.Request(eightsleep.RequestInfo{Context: context.Background()})
+	hooks.Request(eightsleep.RequestInfo{})
+	hooks.Request(eightsleep.RequestInfo{Context: withWatchdog(context.Background())})
+	if requests != 3 {
+		t.Errorf("wrapped hook called %d times, want 3", requests)
+	}
+
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "WATCHDOG=1" {
+		t.Fatalf("notification = %q, %v, want WATCHDOG=1", buf[:n], err)
+	}
+	conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if n, err := conn.Read(buf); err == nil {
+		t.Errorf("unexpected notification %q", buf[:n])
+	}
+}
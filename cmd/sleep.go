@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// sleepCmd represents the sleep command
+var sleepCmd = &cobra.Command{
+	Use:   "sleep",
+	Short: "Show nightly sleep data",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetBool("verbose") {
+			logger.SetLevel(log.DebugLevel)
+		}
+
+		days, _ := cmd.Flags().GetInt("days")
+		if days < 1 {
+			return fmt.Errorf("--days must be at least 1")
+		}
+
+		cli, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer cli.Stop()
+
+		now := time.Now()
+		trends, err := cli.GetTrends(cmd.Context(), now.AddDate(0, 0, -days), now)
+		if err != nil {
+			return err
+		}
+
+		return printOutput(trends, sleepTable(trends))
+	},
+}
+
+func sleepTable(trends *eightsleep.Trends) *tableData {
+	tbl := &tableData{Headers: []string{"Night", "Score", "Asleep", "Deep", "REM", "Light", "In Bed", "Latency"}}
+	for _, day := range trends.Days {
+		tbl.add(
+			day.Day,
+			fmt.Sprintf("%.0f", day.Score),
+			formatSeconds(day.SleepDuration),
+			formatSeconds(day.DeepDuration),
+			formatSeconds(day.RemDuration),
+			formatSeconds(day.LightDuration),
+			formatSeconds(day.PresenceDuration),
+			formatSeconds(day.LatencyAsleepSeconds),
+		)
+	}
+	return tbl
+}
+
+// formatSeconds renders a duration in seconds as e.g. "7h32m"
+func formatSeconds(secs float64) string {
+	d := (time.Duration(secs) * time.Second).Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	if h == 0 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", h, m)
+}
+
+func init() {
+	rootCmd.AddCommand(sleepCmd)
+
+	sleepCmd.Flags().IntP("days", "d", 7, "Number of nights to show")
+}
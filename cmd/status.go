@@ -23,6 +23,7 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/blacktop/clim8/pkg/eightsleep"
 	"github.com/charmbracelet/log"
@@ -34,32 +35,169 @@ import (
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show Eight Sleep status",
-	Args:  cobra.NoArgs,
+	Long: `Show Eight Sleep status.
+
+With --check nothing is printed and the exit code reports whether a side of the
+Pod is in the given state: 0 if it matches, 1 if it does not and 2 on error.
+Temperature checks compare the side's target temperature and match within ±2
+heating levels unless a tolerance is given, in the temperature's unit or app
+scale steps. Use wait to block until the pod actually reaches it.`,
+	Example: "  clim8 status\n  clim8 status --watch\n  clim8 status --check on --side left\n  clim8 status --check temp=68F±1\n  clim8 status --check temp=-3",
+	Args:    cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if viper.GetBool("verbose") {
 			logger.SetLevel(log.DebugLevel)
 		}
 
-		cli, err := eightsleep.NewClient(
-			viper.GetString("email"),
-			viper.GetString("password"),
-			"America/New_York",
-		)
+		if cmd.Flags().Changed("check") {
+			return checkStatus(cmd)
+		}
+		if cmd.Flags().Changed("side") {
+			return fmt.Errorf("--side can only be used with --check")
+		}
+
+		cli, err := newClient(cmd.Context())
 		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
+			return err
 		}
 		defer cli.Stop()
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			interval, _ := cmd.Flags().GetDuration("interval")
+			if interval < time.Second {
+				return fmt.Errorf("--interval must be at least 1s")
+			}
+			return watchStatus(cmd.Context(), cli, interval)
+		}
+
+		status, err := cli.Status(cmd.Context())
+		if err != nil {
+			return err
+		}
+		saveRampRates(cli)
 
-		if err := cli.Start(cmd.Context()); err != nil {
-			return fmt.Errorf("failed to start client: %w", err)
+		if err := printStatus(status); err != nil {
+			return err
 		}
-		logger.Info("STATUS")
-		cli.Status(cmd.Context())
+
+		checkSubscriptionDaily(cmd.Context(), cli)
 
 		return nil
 	},
 }
 
+// checkStatus exits 0 if the side matches the --check state, 1 if it does not and 2 on error
+func checkStatus(cmd *cobra.Command) error {
+	// the exit code is the result, errors are logged once by Execute
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	check, _ := cmd.Flags().GetString("check")
+	sideFlag, _ := cmd.Flags().GetString("side")
+
+	expected, err := parseCheck(check)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+
+	cli, err := newClient(cmd.Context())
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	defer cli.Stop()
+
+	side := cli.Side()
+	if sideFlag != "" {
+		if side, err = parseSide(sideFlag); err != nil {
+			return &exitError{code: 2, err: err}
+		}
+	}
+
+	status, err := cli.Status(cmd.Context())
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	saveRampRates(cli)
+
+	ok, err := expected.matches(status.Side(side), status.Unit)
+	if err != nil {
+		return &exitError{code: 2, err: err}
+	}
+	if !ok {
+		logger.Debug("Status check failed", "check", check, "side", side, "level", status.Side(side).Level)
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+func printStatus(status *eightsleep.PodStatus) error {
+	if !isStructuredOutput() {
+		if status.On() {
+			logger.Info("Eight Sleep is ON")
+		} else {
+			logger.Info("Eight Sleep is OFF")
+		}
+	}
+
+	if err := printOutput(status, statusTable(status)); err != nil {
+		return err
+	}
+
+	if !status.Online {
+		logger.Warn("Pod is offline")
+	}
+	if !status.HasWater {
+		logger.Warn("Pod is out of water")
+	}
+	if status.Priming {
+		logger.Warn("Pod is priming")
+	} else if status.NeedsPriming {
+		logger.Warn("Pod needs priming")
+	}
+
+	return nil
+}
+
+func statusTable(status *eightsleep.PodStatus) *tableData {
+	tbl := &tableData{Headers: []string{"Side", "State", "Activity", "Current", "Target", "Direction", "ETA", "Away"}}
+	for _, side := range []*eightsleep.SideStatus{&status.Left, &status.Right} {
+		state := "off"
+		if side.On {
+			state = "on"
+		}
+		tbl.add(
+			string(side.Side),
+			state,
+			side.Activity,
+			eightsleep.FormatLevel(side.Level, status.Unit),
+			eightsleep.FormatLevel(side.TargetLevel, status.Unit),
+			string(side.Direction),
+			formatETA(side),
+			fmt.Sprint(side.Away),
+		)
+	}
+	return tbl
+}
+
+// formatETA renders the estimated time until a side reaches its target level
+func formatETA(side *eightsleep.SideStatus) string {
+	switch {
+	case !side.On:
+		return ""
+	case side.Direction == eightsleep.Steady:
+		return "at target"
+	case side.ETA > 0:
+		return "~" + side.ETA.Round(time.Minute).String()
+	default:
+		return "—"
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().BoolP("watch", "w", false, "Continuously poll and redraw the status until Ctrl-C")
+	statusCmd.Flags().Duration("interval", 30*time.Second, "Polling interval for --watch")
+	statusCmd.Flags().String("check", "", "Exit 0 if the side is in this state, 1 if not (on, off or temp=68F±1)")
+	statusCmd.Flags().String("side", "", "Side of the Pod to --check (left or right, default your side)")
+	statusCmd.MarkFlagsMutuallyExclusive("check", "watch")
 }
@@ -0,0 +1,141 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// subscriptionWarnWindow is how far ahead of a membership lapse to start warning
+const subscriptionWarnWindow = 14 * 24 * time.Hour
+
+// subscriptionCheckInterval is how often commands such as status check the membership
+const subscriptionCheckInterval = 24 * time.Hour
+
+// subscriptionCmd represents the subscription command
+var subscriptionCmd = &cobra.Command{
+	Use:     "subscription",
+	Aliases: []string{"sub"},
+	Short:   "Show Eight Sleep membership status",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetBool("verbose") {
+			logger.SetLevel(log.DebugLevel)
+		}
+
+		cli, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		defer cli.Stop()
+
+		subs, err := cli.GetSubscriptions(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if err := printOutput(subs, subscriptionsTable(subs)); err != nil {
+			return err
+		}
+		warnSubscriptionLapse(subs)
+
+		return nil
+	},
+}
+
+func subscriptionsTable(subs *eightsleep.Subscriptions) *tableData {
+	tbl := &tableData{Headers: []string{"Plan", "Status", "Renews", "Expires", "Features"}}
+	for _, sub := range subs.Subscriptions {
+		var renews, expires string
+		if end := sub.EndsAt(); !end.IsZero() {
+			expires = end.Local().Format(time.DateOnly)
+		} else if !sub.RenewalDate.IsZero() {
+			renews = sub.RenewalDate.Local().Format(time.DateOnly)
+		}
+		tbl.add(sub.Plan, sub.Status, renews, expires, strings.Join(sub.Features, ", "))
+	}
+	return tbl
+}
+
+// warnSubscriptionLapse logs a warning if the current subscription is inactive or about to end
+func warnSubscriptionLapse(subs *eightsleep.Subscriptions) {
+	sub, ok := subs.Current()
+	if !ok || !sub.Lapsing(subscriptionWarnWindow) {
+		return
+	}
+	kv := []any{"plan", sub.Plan, "status", sub.Status}
+	if end := sub.EndsAt(); !end.IsZero() {
+		kv = append(kv, "expires", end.Local().Format(time.DateOnly))
+	}
+	if len(sub.Features) > 0 {
+		kv = append(kv, "affects", strings.Join(sub.Features, ", "))
+	}
+	logger.Warn("Eight Sleep membership is about to lapse", kv...)
+}
+
+// checkSubscription fetches the account's subscriptions and warns if the membership is about to
+// lapse, reporting whether the check succeeded
+func checkSubscription(ctx context.Context, cli *eightsleep.Client) bool {
+	subs, err := cli.GetSubscriptions(ctx)
+	if err != nil {
+		logger.Debug("Failed to check subscription status", "err", err)
+		return false
+	}
+	warnSubscriptionLapse(subs)
+	return true
+}
+
+// checkSubscriptionDaily checks the membership at most once per subscriptionCheckInterval, so
+// commands run often do not fetch it every time
+func checkSubscriptionDaily(ctx context.Context, cli *eightsleep.Client) {
+	dir, err := configDir()
+	if err != nil {
+		return
+	}
+	path := filepath.Join(dir, "subscription-checked")
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < subscriptionCheckInterval {
+		return
+	}
+	if !checkSubscription(ctx, cli) {
+		return
+	}
+	now := time.Now()
+	if err := os.WriteFile(path, nil, 0600); err == nil {
+		err = os.Chtimes(path, now, now)
+	}
+	if err != nil {
+		logger.Debug("Failed to record subscription check", "err", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(subscriptionCmd)
+}
@@ -23,6 +23,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/blacktop/clim8/pkg/eightsleep"
 	"github.com/charmbracelet/log"
@@ -32,45 +33,91 @@ import (
 
 // tempCmd represents the temp command
 var tempCmd = &cobra.Command{
-	Use:     "temp <temperature>",
-	Short:   "Set the temperature of Eight Sleep Pod",
-	Long:    "Set the temperature of Eight Sleep Pod. Temperature must include unit (F for Fahrenheit or C for Celsius).",
-	Example: "  clim8 temp 68F\n  clim8 temp 24C\n  clim8 temp 72F",
-	Args:    cobra.ExactArgs(1),
+	Use:   "temp [temperature]",
+	Short: "Set the temperature of Eight Sleep Pod",
+	Long: `Set the temperature of Eight Sleep Pod.
+
+The temperature can be given as:
+  - an absolute temperature with unit (F for Fahrenheit or C for Celsius)
+  - an absolute temperature without unit, in your preferred unit (see --unit)
+  - a value on the app's -10..+10 scale
+  - a raw heating level (-100..100) via --level
+  - a relative adjustment with a sign and unit, e.g. +2F or -1C (see also warmer/cooler)
+
+Note: a relative adjustment needs a unit. A signed value without one, e.g. +2,
+is an absolute setting on the app's scale, not 2 steps warmer. Use
+'clim8 warmer 2' to adjust by app scale steps.`,
+	Example: "  clim8 temp 68F\n  clim8 temp 24C\n  clim8 temp 68\n  clim8 temp +2\n  clim8 temp -3\n  clim8 temp --level -30\n  clim8 temp +2F\n  clim8 temp -1C",
+	Args:    cobra.MaximumNArgs(1),
+	// negative values such as -3 are arguments, not flags
+	Annotations: map[string]string{negativeArgsAnnotation: "true"},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if viper.GetBool("verbose") {
 			logger.SetLevel(log.DebugLevel)
 		}
 
-		temperature := args[0]
-
-		cli, err := eightsleep.NewClient(
-			viper.GetString("email"),
-			viper.GetString("password"),
-			"America/New_York",
+		var (
+			level  int
+			target string
 		)
+		switch {
+		case cmd.Flags().Changed("level") && len(args) > 0:
+			return fmt.Errorf("cannot use both a temperature argument and --level")
+		case cmd.Flags().Changed("level"):
+			level, _ = cmd.Flags().GetInt("level")
+			if level < eightsleep.MIN_LEVEL || level > eightsleep.MAX_LEVEL {
+				return fmt.Errorf("level %d out of range (%d..%d)", level, eightsleep.MIN_LEVEL, eightsleep.MAX_LEVEL)
+			}
+		case len(args) == 1 && isRelativeTemperature(args[0]):
+			return adjustTemperature(cmd, args[0])
+		case len(args) == 1:
+			// validate the syntax now, the unit is resolved once logged in
+			if err := validateTemperature(args[0]); err != nil {
+				return err
+			}
+			target = args[0]
+			if strings.HasPrefix(target, "+") {
+				logger.Info("Setting an absolute app scale value, use warmer/cooler or add a unit (+2F) to adjust relative to the current level", "value", target)
+			}
+		default:
+			return fmt.Errorf("a temperature argument or --level is required")
+		}
+
+		cli, err := newClient(cmd.Context())
 		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
+			return err
 		}
 		defer cli.Stop()
 
-		if err := cli.Start(cmd.Context()); err != nil {
-			return fmt.Errorf("failed to start client: %w", err)
+		if target != "" {
+			level, err = eightsleep.ParseTemperature(target, cli.Unit())
+			if err != nil {
+				return err
+			}
 		}
 
 		if err := cli.TurnOn(cmd.Context()); err != nil {
 			return err
 		}
 
-		if err := cli.SetTemperature(cmd.Context(), temperature); err != nil {
+		if err := cli.SetHeatingLevel(cmd.Context(), level); err != nil {
 			return err
 		}
-		logger.Info("Temperature Set", "temp", temperature)
+		logger.Info("Temperature Set", "level", eightsleep.FormatLevel(level, cli.Unit()))
 
 		return nil
 	},
 }
 
+// isRelativeTemperature reports whether the value is a signed adjustment with a unit, e.g. "+2F"
+func isRelativeTemperature(value string) bool {
+	value = strings.ToUpper(value)
+	return (strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-")) &&
+		(strings.HasSuffix(value, "F") || strings.HasSuffix(value, "C"))
+}
+
 func init() {
 	rootCmd.AddCommand(tempCmd)
+
+	tempCmd.Flags().Int("level", 0, "Raw heating level (-100..100)")
 }
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMoveNegativeArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"temp", "-3"}, want: []string{"temp", "--", "-3"}},
+		{args: []string{"temp", "-1C", "-V"}, want: []string{"temp", "-V", "--", "-1C"}},
+		{args: []string{"temp", "--unit", "C", "-3"}, want: []string{"temp", "--unit", "C", "--", "-3"}},
+		{args: []string{"temp", "--level", "-30"}, want: []string{"temp", "--level", "-30"}},
+		{args: []string{"temp", "--level=-30"}, want: []string{"temp", "--level=-30"}},
+		{args: []string{"temp", "--", "-3"}, want: []string{"temp", "--", "-3"}},
+		{args: []string{"temp", "68F"}, want: []string{"temp", "68F"}},
+		// only annotated commands take negative arguments
+		{args: []string{"status", "-3"}, want: []string{"status", "-3"}},
+	}
+	for _, tt := range tests {
+		if got := moveNegativeArgs(slices.Clone(tt.args)); !slices.Equal(got, tt.want) {
+			t.Errorf("moveNegativeArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -22,11 +22,6 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
-
-	"github.com/alecthomas/chroma/v2/quick"
 	"github.com/blacktop/clim8/pkg/eightsleep"
 	"github.com/charmbracelet/log"
 	"github.com/spf13/cobra"
@@ -37,24 +32,23 @@ import (
 var tracksCmd = &cobra.Command{
 	Use:   "tracks",
 	Short: "List audio tracks",
-	Args:  cobra.NoArgs,
+	Long: `List audio tracks.
+
+Only pods that report the audio capability play audio, see 'clim8 device' or 'clim8 feats'.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if viper.GetBool("verbose") {
 			logger.SetLevel(log.DebugLevel)
 		}
 
-		cli, err := eightsleep.NewClient(
-			viper.GetString("email"),
-			viper.GetString("password"),
-			"America/New_York",
-		)
+		cli, err := newClient(cmd.Context())
 		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
+			return err
 		}
 		defer cli.Stop()
 
-		if err := cli.Start(cmd.Context()); err != nil {
-			return fmt.Errorf("failed to start client: %w", err)
+		if err := requireCapability(cli, eightsleep.CapAudio); err != nil {
+			return err
 		}
 
 		tracks, err := cli.GetAudioTracks(cmd.Context())
@@ -62,19 +56,38 @@ var tracksCmd = &cobra.Command{
 			return err
 		}
 
-		logger.Info("AUDIO TRACKS")
-		jsonData, err := json.MarshalIndent(tracks, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal json: %v", err)
-		}
-		if err := quick.Highlight(os.Stdout, string(jsonData)+"\n", "json", "terminal256", "nord"); err != nil {
-			return fmt.Errorf("failed to highlight json: %v", err)
+		if err := printOutput(tracks, tracksTable(tracks)); err != nil {
+			return err
 		}
 
 		return nil
 	},
 }
 
+func tracksTable(data map[string]any) *tableData {
+	tbl := &tableData{Headers: []string{"Category", "ID", "Track"}}
+	categories, _ := data["categories"].([]any)
+	for _, c := range categories {
+		category, _ := c.(map[string]any)
+		tracks, _ := category["tracks"].([]any)
+		for _, t := range tracks {
+			track, _ := t.(map[string]any)
+			tbl.add(stringField(category, "name", "id"), stringField(track, "id"), stringField(track, "name", "title"))
+		}
+	}
+	return tbl
+}
+
+// stringField returns the first of the given keys that holds a string value
+func stringField(m map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
 func init() {
 	rootCmd.AddCommand(tracksCmd)
 }
@@ -0,0 +1,131 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// waitCmd represents the wait command
+var waitCmd = &cobra.Command{
+	Use:   "wait",
+	Short: "Wait until the Pod reaches its target temperature",
+	Long: `Wait until the Pod reaches its target temperature.
+
+Polls the Pod until the current heating level of a side is within --tolerance
+of its target level. Exits 0 once the target is reached, 1 if --timeout expires
+first and 2 if the Pod is offline, the side is off, a flag is invalid or an error
+occurs.`,
+	Example: "  clim8 temp 65F && clim8 wait --timeout 45m && notify-send 'Bed is ready'",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetBool("verbose") {
+			logger.SetLevel(log.DebugLevel)
+		}
+
+		// the exit code is the result, errors are logged once by Execute
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		tolerance, _ := cmd.Flags().GetInt("tolerance")
+		sideFlag, _ := cmd.Flags().GetString("side")
+		if interval < time.Second {
+			return &exitError{code: 2, err: fmt.Errorf("--interval must be at least 1s")}
+		}
+		if tolerance < 0 {
+			return &exitError{code: 2, err: fmt.Errorf("--tolerance must not be negative")}
+		}
+
+		cli, err := newClient(cmd.Context())
+		if err != nil {
+			return &exitError{code: 2, err: err}
+		}
+		defer cli.Stop()
+
+		side := cli.Side()
+		if sideFlag != "" {
+			side, err = parseSide(sideFlag)
+			if err != nil {
+				return &exitError{code: 2, err: err}
+			}
+		}
+
+		ctx := cmd.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		logger.Info("Waiting for target", "side", side, "tolerance", tolerance, "timeout", timeout)
+		status, err := cli.WaitForTarget(ctx, side, tolerance, interval)
+		saveRampRates(cli)
+		switch {
+		case err == nil:
+			s := status.Side(side)
+			logger.Info("Target Reached", "side", side, "temperature", eightsleep.FormatLevel(s.Level, status.Unit))
+			return nil
+		case errors.Is(err, context.DeadlineExceeded) && status == nil:
+			return &exitError{code: 1, err: fmt.Errorf("timed out after %s", timeout)}
+		case errors.Is(err, context.DeadlineExceeded):
+			s := status.Side(side)
+			return &exitError{code: 1, err: fmt.Errorf("timed out after %s: %s side at %s, target %s", timeout, side,
+				eightsleep.FormatLevel(s.Level, status.Unit), eightsleep.FormatLevel(s.TargetLevel, status.Unit))}
+		default:
+			return &exitError{code: 2, err: err}
+		}
+	},
+}
+
+// parseSide parses a side of the Pod, e.g. "left" or "right"
+func parseSide(s string) (eightsleep.Side, error) {
+	switch eightsleep.Side(s) {
+	case eightsleep.Left, eightsleep.Right:
+		return eightsleep.Side(s), nil
+	default:
+		return "", fmt.Errorf("invalid side %q (expected left or right)", s)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(waitCmd)
+
+	// exit 1 means timed out, so flags that fail to parse exit 2 like other errors
+	waitCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &exitError{code: 2, err: err}
+	})
+
+	waitCmd.Flags().String("side", "", "Side of the Pod to wait for (left or right, default your side)")
+	waitCmd.Flags().Duration("timeout", 30*time.Minute, "Give up after this long (0 waits forever)")
+	waitCmd.Flags().Int("tolerance", 2, "Maximum difference in heating levels to consider the target reached")
+	waitCmd.Flags().Duration("interval", 30*time.Second, "Polling interval")
+}
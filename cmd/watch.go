@@ -0,0 +1,172 @@
+/*
+Copyright © 2025 blacktop
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/blacktop/clim8/pkg/eightsleep"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const progressBarWidth = 24
+
+var (
+	panelStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1).
+			Width(40)
+	panelTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	dimStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	warnStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("204"))
+	heatStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("209"))
+	coolStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+)
+
+// rampObservation is the first level seen for a side since its target last changed
+type rampObservation struct {
+	level  int
+	target int
+}
+
+// watchState tracks ramp progress across polls
+type watchState struct {
+	start map[eightsleep.Side]rampObservation
+}
+
+func (w *watchState) observe(side *eightsleep.SideStatus) rampObservation {
+	obs, ok := w.start[side.Side]
+	if !ok || obs.target != side.TargetLevel {
+		obs = rampObservation{level: side.Level, target: side.TargetLevel}
+		w.start[side.Side] = obs
+	}
+	return obs
+}
+
+// watchStatus polls the pod status on an interval and redraws a panel per side until interrupted
+func watchStatus(ctx context.Context, cli *eightsleep.Client, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	state := &watchState{start: make(map[eightsleep.Side]rampObservation)}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := cli.Status(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		saveRampRates(cli)
+		fmt.Print("\033[H\033[2J")
+		fmt.Println(renderWatch(state, status, err, time.Now()))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func renderWatch(state *watchState, status *eightsleep.PodStatus, err error, now time.Time) string {
+	footer := dimStyle.Render(fmt.Sprintf("Updated %s · Ctrl-C to exit", now.Format(time.TimeOnly)))
+	if err != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, warnStyle.Render("Error: "+err.Error()), footer)
+	}
+
+	var panels []string
+	for _, side := range []*eightsleep.SideStatus{&status.Left, &status.Right} {
+		panels = append(panels, renderSidePanel(side, state.observe(side), status.Unit))
+	}
+
+	var pod []string
+	if status.Online {
+		pod = append(pod, "online")
+	} else {
+		pod = append(pod, warnStyle.Render("offline"))
+	}
+	if status.HasWater {
+		pod = append(pod, "water ok")
+	} else {
+		pod = append(pod, warnStyle.Render("out of water"))
+	}
+	if status.Priming {
+		pod = append(pod, warnStyle.Render("priming"))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		lipgloss.JoinHorizontal(lipgloss.Top, panels...),
+		"Pod: "+strings.Join(pod, " · "),
+		footer,
+	)
+}
+
+func renderSidePanel(side *eightsleep.SideStatus, start rampObservation, unit eightsleep.UnitOfTemperature) string {
+	title := panelTitleStyle.Render(strings.ToUpper(string(side.Side)))
+	if !side.On {
+		return panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, title, dimStyle.Render("off")))
+	}
+	if side.Away {
+		title += dimStyle.Render(" (away)")
+	}
+
+	direction := string(side.Direction)
+	switch side.Direction {
+	case eightsleep.Heating:
+		direction = heatStyle.Render(direction)
+	case eightsleep.Cooling:
+		direction = coolStyle.Render(direction)
+	}
+
+	return panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		dimStyle.Render(side.Activity),
+		"Current: "+eightsleep.FormatLevel(side.Level, unit),
+		"Target:  "+eightsleep.FormatLevel(side.TargetLevel, unit),
+		direction+" "+progressBar(start.level, side.Level, side.TargetLevel),
+		"ETA:     "+formatETA(side),
+	))
+}
+
+// progressBar renders how far the level has moved from where the ramp started towards the target
+func progressBar(from, current, target int) string {
+	pct := 1.0
+	if target != from {
+		pct = math.Max(0, math.Min(1, float64(current-from)/float64(target-from)))
+	}
+	filled := int(math.Round(pct * progressBarWidth))
+	return fmt.Sprintf("%s%s %3.0f%%",
+		strings.Repeat("█", filled),
+		dimStyle.Render(strings.Repeat("░", progressBarWidth-filled)),
+		pct*100,
+	)
+}
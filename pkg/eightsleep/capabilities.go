@@ -0,0 +1,130 @@
+package eightsleep
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Capability is a feature of the account or its devices, named as the API reports it
+type Capability string
+
+const (
+	// CapCooling and CapElevation are reported in the profile's features
+	CapCooling   Capability = "cooling"
+	CapElevation Capability = "elevation"
+	// CapAudio is reported by pods with speakers
+	CapAudio Capability = "audio"
+	// CapBase is an adjustable base expected as a peripheral of the pod
+	CapBase Capability = "base"
+)
+
+// Capabilities maps every capability of the account and its devices to the source it came from
+// ("release", "profile", "device" or "peripheral")
+type Capabilities map[Capability]string
+
+// Has reports whether the capability is supported
+func (c Capabilities) Has(cap Capability) bool {
+	_, ok := c[cap]
+	return ok
+}
+
+// HasBase reports whether the pod has an adjustable base
+func (c Capabilities) HasBase() bool {
+	return c.Has(CapBase) || c.Has(CapElevation)
+}
+
+// List returns the capabilities sorted by name
+func (c Capabilities) List() []Capability {
+	caps := make([]Capability, 0, len(c))
+	for cap := range c {
+		caps = append(caps, cap)
+	}
+	sort.Slice(caps, func(i, j int) bool { return caps[i] < caps[j] })
+	return caps
+}
+
+// Diff returns the capabilities added and removed since prev
+func (c Capabilities) Diff(prev Capabilities) (added, removed []Capability) {
+	for _, cap := range c.List() {
+		if !prev.Has(cap) {
+			added = append(added, cap)
+		}
+	}
+	for _, cap := range prev.List() {
+		if !c.Has(cap) {
+			removed = append(removed, cap)
+		}
+	}
+	return added, removed
+}
+
+func (c Capabilities) add(cap, source string) {
+	if cap == "" {
+		return
+	}
+	if _, ok := c[Capability(cap)]; !ok {
+		c[Capability(cap)] = source
+	}
+}
+
+// addRelease flattens the release features response, keeping every enabled flag
+func (c Capabilities) addRelease(prefix string, feats map[string]any) {
+	for key, val := range feats {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		switch v := val.(type) {
+		case bool:
+			if v {
+				c.add(name, "release")
+			}
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					c.add(s, "release")
+				}
+			}
+		case map[string]any:
+			c.addRelease(name, v)
+		}
+	}
+}
+
+// Capabilities returns the capabilities of the profile and devices fetched when the client was
+// started or last refreshed, without making a request
+func (c *Client) Capabilities() Capabilities {
+	caps := make(Capabilities)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.me != nil {
+		for _, f := range c.me.Features {
+			caps.add(f, "profile")
+		}
+	}
+	for _, device := range c.devices {
+		for _, f := range device.Features {
+			caps.add(f, "device")
+		}
+		for _, p := range device.ExpectedPeripherals {
+			caps.add(p.PeripheralType, "peripheral")
+		}
+	}
+	return caps
+}
+
+// GetCapabilities merges release features with the profile features, device features and
+// expected peripherals into a single capability set
+func (c *Client) GetCapabilities(ctx context.Context) (Capabilities, error) {
+	feats, err := c.GetReleaseFeatures(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capabilities: %w", err)
+	}
+
+	caps := c.Capabilities()
+	caps.addRelease("", feats)
+	return caps, nil
+}
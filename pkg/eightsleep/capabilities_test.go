@@ -0,0 +1,38 @@
+package eightsleep
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestClientCapabilities(t *testing.T) {
+	var device Device
+	if err := json.Unmarshal([]byte(`{"features":["audio"],"expectedPeripherals":[{"peripheralType":"base"}]}`), &device); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		client  *Client
+		want    []Capability
+		hasBase bool
+	}{
+		{name: "not started", client: &Client{}},
+		{name: "pod 3", client: &Client{me: &Profile{Features: []string{"cooling"}}}, want: []Capability{CapCooling}},
+		{name: "elevation", client: &Client{me: &Profile{Features: []string{"cooling", "elevation"}}}, want: []Capability{CapCooling, CapElevation}, hasBase: true},
+		{name: "peripherals", client: &Client{me: &Profile{Features: []string{"cooling"}}, devices: []Device{device}}, want: []Capability{CapAudio, CapBase, CapCooling}, hasBase: true},
+	}
+	for _, tt := range tests {
+		caps := tt.client.Capabilities()
+		got := caps.List()
+		if len(got) != len(tt.want) || caps.HasBase() != tt.hasBase {
+			t.Errorf("%s: Capabilities() = %v, HasBase %v, want %v, %v", tt.name, got, caps.HasBase(), tt.want, tt.hasBase)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: Capabilities() = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
@@ -5,17 +5,15 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/alecthomas/chroma/v2/quick"
 	"github.com/charmbracelet/log"
 )
 
@@ -34,37 +32,91 @@ const (
 	MAX_TEMP_F = 110
 	MIN_TEMP_C = 13
 	MAX_TEMP_C = 44
+
+	MIN_LEVEL = -100
+	MAX_LEVEL = 100
+	MIN_SCALE = -10
+	MAX_SCALE = 10
 )
 
+// RequestTimeout is the longest a single API request may take
+const RequestTimeout = defaultTimeoutSec * time.Second
+
 var POSSIBLE_SLEEP_STAGES = []string{"bedTimeLevel", "initialSleepLevel", "finalSleepLevel"}
 
+// Host is an Eight Sleep API host
+type Host string
+
+const (
+	AppHost    Host = "app"
+	ClientHost Host = "client"
+)
+
+// hostURLs are the base URLs of the API hosts, request paths include the endpoint version
+var hostURLs = map[Host]string{
+	AppHost:    appAPIURL,
+	ClientHost: strings.TrimSuffix(clientAPIURL, "/v1"),
+}
+
+// APIError is returned when the API responds with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Status)
+}
+
+var (
+	ErrDeviceOffline = errors.New("device is offline")
+	ErrSideOff       = errors.New("side is off")
+)
+
 type Client struct {
 	mu sync.RWMutex
+	// authMu serializes logins so concurrent requests share one new token
+	authMu sync.Mutex
 
 	email, password string
 	tz              *time.Location
+	// tzName is the IANA name of tz as configured or reported by the device, sent to the API
+	tzName string
 
 	clientID, clientSecret string
 
 	http  *http.Client
 	token *Token
 
-	isPod   bool
-	hasBase bool
+	unit  UnitOfTemperature
+	ramps *RampRates
+	hooks Hooks
 
 	me      *Profile
 	devices []Device
 }
 
+// NewClient creates a new Eight Sleep client. If tz is empty the timezone of the user's device
+// is used once the client is started.
 func NewClient(email, password, tz string) (*Client, error) {
-	loc, err := time.LoadLocation(tz)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load timezone %s: %w", tz, err)
+	var loc *time.Location
+	if tz != "" {
+		var err error
+		loc, err = time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timezone %s: %w", tz, err)
+		}
+		if loc == time.Local {
+			// "Local" is no zone name the API knows, send the device's instead
+			tz = ""
+		}
 	}
 	return &Client{
 		email:        email,
 		password:     password,
 		tz:           loc,
+		tzName:       tz,
 		clientID:     knownClientID,
 		clientSecret: knownClientSecret,
 		http: &http.Client{
@@ -85,18 +137,48 @@ func (c *Client) Start(ctx context.Context) error {
 	if err := c.fetchDevices(ctx); err != nil {
 		return fmt.Errorf("failed to fetch devices: %w", err)
 	}
+	c.mu.Lock()
+	if c.tz == nil {
+		c.tz = c.deviceLocation()
+	}
+	if c.tzName == "" {
+		c.tzName = c.deviceTimezone()
+	}
+	c.mu.Unlock()
 	return nil
 }
 
 func (c *Client) Stop() { /* nothing to close right now */ }
 
+// Refresh fetches the profile and devices again, so a long-lived client picks up changes to the
+// user's side, preferred unit or devices
+func (c *Client) Refresh(ctx context.Context) error {
+	if err := c.fetchProfile(ctx); err != nil {
+		return fmt.Errorf("failed to fetch profile: %w", err)
+	}
+	if err := c.fetchDevices(ctx); err != nil {
+		return fmt.Errorf("failed to fetch devices: %w", err)
+	}
+	return nil
+}
+
+// Location returns the timezone used for date based queries
+func (c *Client) Location() *time.Location {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.tz == nil {
+		return time.Local
+	}
+	return c.tz
+}
+
 func (c *Client) RoomTemperature(ctx context.Context) (float64, error) {
 	panic("not implemented")
 	// TODO: get trends and calculate room temperature average (average both sides if both are active)
 }
 
 func (c *Client) TurnOn(ctx context.Context) error {
-	url := fmt.Sprintf("%s/v1/users/%s/temperature/pod?ignoreDeviceErrors=false", appAPIURL, c.me.ID)
+	url := fmt.Sprintf("%s/v1/users/%s/temperature/pod?ignoreDeviceErrors=false", appAPIURL, c.userID())
 	body := map[string]any{
 		"currentState": map[string]string{"type": "smart"},
 	}
@@ -115,7 +197,7 @@ func (c *Client) TurnOn(ctx context.Context) error {
 }
 
 func (c *Client) TurnOff(ctx context.Context) error {
-	url := fmt.Sprintf("%s/v1/users/%s/temperature/pod?ignoreDeviceErrors=false", appAPIURL, c.me.ID)
+	url := fmt.Sprintf("%s/v1/users/%s/temperature/pod?ignoreDeviceErrors=false", appAPIURL, c.userID())
 	body := map[string]any{
 		"currentState": map[string]string{"type": "off"},
 	}
@@ -134,7 +216,7 @@ func (c *Client) TurnOff(ctx context.Context) error {
 }
 
 func (c *Client) GetTemperatureState(ctx context.Context) (*TemperatureState, error) {
-	url := fmt.Sprintf("%s/v1/users/%s/temperature/pod?ignoreDeviceErrors=false", appAPIURL, c.me.ID)
+	url := fmt.Sprintf("%s/v1/users/%s/temperature/pod?ignoreDeviceErrors=false", appAPIURL, c.userID())
 	var resp TemperatureState
 	if err := c.doJSON(ctx, http.MethodGet, url, nil, &resp); err != nil {
 		return nil, fmt.Errorf("failed to get temperature state: %w", err)
@@ -142,25 +224,25 @@ func (c *Client) GetTemperatureState(ctx context.Context) (*TemperatureState, er
 	return &resp, nil
 }
 
+// SetTemperature sets the temperature from a string such as "68F", "20C", "-3" (app scale) or
+// "68" (in the preferred unit)
 func (c *Client) SetTemperature(ctx context.Context, degrees string) error {
-	// parse degrees
-	var unit UnitOfTemperature
-	switch {
-	case strings.HasSuffix(degrees, "C"):
-		unit = Celsius
-	case strings.HasSuffix(degrees, "F"):
-		unit = Fahrenheit
-	default:
-		return fmt.Errorf("invalid temperature format: %s (must end with C or F)", degrees)
-	}
-	temp, err := strconv.Atoi(strings.Trim(degrees, "CF"))
+	level, err := ParseTemperature(degrees, c.Unit())
 	if err != nil {
-		return fmt.Errorf("invalid temperature value: %s", degrees)
+		return err
+	}
+	return c.SetHeatingLevel(ctx, level)
+}
+
+// SetHeatingLevel sets the raw heating level (-100..100)
+func (c *Client) SetHeatingLevel(ctx context.Context, level int) error {
+	if level < MIN_LEVEL || level > MAX_LEVEL {
+		return fmt.Errorf("heating level %d out of range (%d..%d)", level, MIN_LEVEL, MAX_LEVEL)
 	}
 
-	url := fmt.Sprintf("%s/v1/users/%s/temperature/pod?ignoreDeviceErrors=false", appAPIURL, c.me.ID)
+	url := fmt.Sprintf("%s/v1/users/%s/temperature/pod?ignoreDeviceErrors=false", appAPIURL, c.userID())
 	body := map[string]any{
-		"currentLevel": tempToHeatingLevel(temp, unit),
+		"currentLevel": level,
 	}
 	var resp TemperatureState
 	if err := c.doJSON(ctx, http.MethodPut, url, body, &resp); err != nil {
@@ -168,7 +250,7 @@ func (c *Client) SetTemperature(ctx context.Context, degrees string) error {
 	}
 
 	for _, device := range resp.Devices {
-		if device.CurrentLevel != tempToHeatingLevel(temp, unit) {
+		if device.CurrentLevel != level {
 			return fmt.Errorf("failed to set temperature on device %s: %s", device.Device.DeviceID, device.CurrentState.Type)
 		}
 	}
@@ -176,30 +258,199 @@ func (c *Client) SetTemperature(ctx context.Context, degrees string) error {
 	return nil
 }
 
-func (c *Client) Info(ctx context.Context) (map[string]any, error) {
-	if err := c.fetchTrends(ctx); err != nil {
+// TemperatureAdjustment is the outcome of AdjustTemperature
+type TemperatureAdjustment struct {
+	From, To int
+	// WasOff is set when the user's side was off before the adjustment
+	WasOff bool
+}
+
+// Changed reports whether the adjustment changed the level, it does not when already at a limit
+func (a TemperatureAdjustment) Changed() bool {
+	return a.From != a.To
+}
+
+// AdjustTemperature applies a relative adjustment such as "+2F", "-1C" or "+1" (app scale) to the
+// current heating level. The state is read first: when the level is already at its limit nothing
+// is changed, otherwise a side that is off is turned on before the new level is set.
+func (c *Client) AdjustTemperature(ctx context.Context, delta string) (*TemperatureAdjustment, error) {
+	d, unit, err := ParseTemperatureDelta(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := c.GetTemperatureState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, on, err := c.currentLevel(state)
+	if err != nil {
+		return nil, err
+	}
+
+	adj := &TemperatureAdjustment{From: current, To: AdjustLevel(current, d, unit), WasOff: !on}
+	if !adj.Changed() {
+		return adj, nil
+	}
+	if !on {
+		if err := c.TurnOn(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.SetHeatingLevel(ctx, adj.To); err != nil {
+		return nil, err
+	}
+	return adj, nil
+}
+
+// Info fetches a read-only report of the account; with no sections given every section is included
+func (c *Client) Info(ctx context.Context, sections ...InfoSection) (*InfoReport, error) {
+	if len(sections) == 0 {
+		sections = InfoSections
+	}
+
+	report := &InfoReport{}
+	for _, section := range sections {
+		var err error
+		switch section {
+		case InfoTrends:
+			now := time.Now().In(c.Location())
+			report.Trends, err = c.GetTrends(ctx, now.AddDate(0, 0, -1), now)
+		case InfoIntervals:
+			report.Intervals, err = c.GetIntervals(ctx)
+		case InfoRoutines:
+			report.Routines, err = c.GetRoutines(ctx)
+		case InfoHealthSurvey:
+			report.HealthSurvey, err = c.GetHealthSurveyTestDrive(ctx)
+		case InfoSubscriptions:
+			report.Subscriptions, err = c.GetSubscriptions(ctx)
+		case InfoAutopilot:
+			report.Autopilot, err = c.GetAutopilotDetails(ctx)
+		default:
+			return nil, fmt.Errorf("unknown info section '%s'", section)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// GetTrends returns the nightly sleep data between the given dates
+func (c *Client) GetTrends(ctx context.Context, from, to time.Time) (*Trends, error) {
+	url, err := url.Parse(clientAPIURL + "/users/" + c.userID() + "/trends")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse trends URL: %w", err)
+	}
+	q := url.Query()
+	loc := c.Location()
+	if tz := c.timezoneName(); tz != "" {
+		q.Add("tz", tz)
+	}
+	q.Add("from", from.In(loc).Format(time.DateOnly))
+	q.Add("to", to.In(loc).Format(time.DateOnly))
+	q.Add("include-main", "false")
+	q.Add("include-all-sessions", "true")
+	q.Add("model-version", "v2")
+	url.RawQuery = q.Encode()
+	var data Trends
+	if err := c.doJSON(ctx, http.MethodGet, url.String(), nil, &data); err != nil {
 		return nil, fmt.Errorf("failed to fetch trends: %w", err)
 	}
-	if err := c.fetchIntervals(ctx); err != nil {
+	return &data, nil
+}
+
+// GetIntervals returns the user's recent sleep intervals
+func (c *Client) GetIntervals(ctx context.Context) (map[string]any, error) {
+	url := clientAPIURL + "/users/" + c.userID() + "/intervals"
+	var data map[string]any
+	if err := c.doJSON(ctx, http.MethodGet, url, nil, &data); err != nil {
 		return nil, fmt.Errorf("failed to fetch intervals: %w", err)
 	}
-	if err := c.fetchRoutines(ctx); err != nil {
+	return data, nil
+}
+
+// GetHealthSurveyTestDrive returns the health survey test drive state
+func (c *Client) GetHealthSurveyTestDrive(ctx context.Context) (map[string]any, error) {
+	url := appAPIURL + "/v1/health-survey/test-drive"
+	var data map[string]any
+	if err := c.doJSON(ctx, http.MethodGet, url, nil, &data); err != nil {
+		return nil, fmt.Errorf("failed to fetch health survey test drive: %w", err)
+	}
+	return data, nil
+}
+
+// GetRoutines returns the user's routines, one-off alarms and the next alarm
+func (c *Client) GetRoutines(ctx context.Context) (*Routines, error) {
+	url := appAPIURL + "/v2/users/" + c.userID() + "/routines"
+	var data Routines
+	if err := c.doJSON(ctx, http.MethodGet, url, nil, &data); err != nil {
 		return nil, fmt.Errorf("failed to fetch routines: %w", err)
 	}
-	if err := c.fetchHealthSurveyTestDrive(ctx); err != nil {
-		return nil, fmt.Errorf("failed to fetch health survey test drive: %w", err)
+	return &data, nil
+}
+
+// Do sends an authenticated request to an endpoint not covered by the client yet and decodes
+// the JSON response into out. The {me} and {device} placeholders in path are replaced with the
+// user's and the current device's IDs, e.g. "/v1/users/{me}/release-features".
+func (c *Client) Do(ctx context.Context, method string, host Host, path string, payload, out any) error {
+	base, ok := hostURLs[host]
+	if !ok {
+		return fmt.Errorf("unknown API host '%s'", host)
+	}
+	path = strings.NewReplacer("{me}", c.userID(), "{device}", c.deviceID()).Replace(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
 	}
-	if err := c.fetchSubscriptions(ctx); err != nil {
+	return c.doJSON(ctx, method, base+path, payload, out)
+}
+
+// Devices returns the devices fetched when the client was started or last refreshed
+func (c *Client) Devices() []Device {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Device(nil), c.devices...)
+}
+
+// GetSubscriptions returns the account's membership subscriptions
+func (c *Client) GetSubscriptions(ctx context.Context) (*Subscriptions, error) {
+	url := appAPIURL + "/v3/users/" + c.userID() + "/subscriptions"
+	var data Subscriptions
+	if err := c.doJSON(ctx, http.MethodGet, url, nil, &data); err != nil {
 		return nil, fmt.Errorf("failed to fetch subscriptions: %w", err)
 	}
-	if err := c.fetchAutopilotDetails(ctx); err != nil {
+	return &data, nil
+}
+
+// GetAutopilotDetails returns whether autopilot is enabled and the adjustments it has made recently
+func (c *Client) GetAutopilotDetails(ctx context.Context) (*AutopilotDetails, error) {
+	url := appAPIURL + "/v1/users/" + c.userID() + "/autopilotDetails"
+	var data AutopilotDetails
+	if err := c.doJSON(ctx, http.MethodGet, url, nil, &data); err != nil {
 		return nil, fmt.Errorf("failed to fetch autopilot details: %w", err)
 	}
-	return nil, nil
+	return &data, nil
+}
+
+// SetAutopilot enables or disables autopilot's automatic temperature adjustments
+func (c *Client) SetAutopilot(ctx context.Context, enabled bool) error {
+	url := appAPIURL + "/v1/users/" + c.userID() + "/level-suggestions-mode"
+	body := map[string]any{
+		"autoPilotEnabled": enabled,
+	}
+	var resp map[string]any
+	if err := c.doJSON(ctx, http.MethodPut, url, body, &resp); err != nil {
+		return fmt.Errorf("failed to set autopilot: %w", err)
+	}
+	c.mu.Lock()
+	c.me.AutopilotEnabled = enabled
+	c.mu.Unlock()
+	return nil
 }
 
 func (c *Client) GetReleaseFeatures(ctx context.Context) (map[string]any, error) {
-	url := appAPIURL + "/v1/users/" + c.me.ID + "/release-features"
+	url := appAPIURL + "/v1/users/" + c.userID() + "/release-features"
 	var data map[string]any
 	if err := c.doJSON(ctx, http.MethodGet, url, nil, &data); err != nil {
 		return nil, fmt.Errorf("failed to fetch release features: %w", err)
@@ -215,7 +466,7 @@ func (c *Client) GetAudioTracks(ctx context.Context) (map[string]any, error) {
 	}
 	categories := data["categories"].([]any)
 	for idx, category := range categories {
-		url := appAPIURL + "/v1/users/" + c.me.ID + "/audio/tracks?category=" + category.(map[string]any)["id"].(string)
+		url := appAPIURL + "/v1/users/" + c.userID() + "/audio/tracks?category=" + category.(map[string]any)["id"].(string)
 		var tracks map[string]any
 		if err := c.doJSON(ctx, http.MethodGet, url, nil, &tracks); err != nil {
 			return nil, fmt.Errorf("failed to fetch audio tracks: %w", err)
@@ -234,7 +485,7 @@ func (c *Client) GetAudioTracks(ctx context.Context) (map[string]any, error) {
 }
 
 func (c *Client) SetAlarm(ctx context.Context, time string) error {
-	url := fmt.Sprintf("%s/v2/users/%s/routines/%s", appAPIURL, c.me.ID, "1234")
+	url := fmt.Sprintf("%s/v2/users/%s/routines/%s", appAPIURL, c.userID(), "1234")
 	body := map[string]any{
 		"id":      "1234",
 		"alarms":  []any{},
@@ -273,42 +524,167 @@ func (c *Client) SetAlarm(ctx context.Context, time string) error {
 		return fmt.Errorf("failed to set alarm: %w", err)
 	}
 	// TODO: check if alarm was set successfully via response JSON
-	if log.GetLevel() == log.DebugLevel {
-		if err := prettyPrint(resp); err != nil {
-			return fmt.Errorf("failed to pretty print response: %w", err)
-		}
-	}
 	return nil
 }
 
-func (c *Client) Status(ctx context.Context) {
-	for _, device := range c.devices {
-		if device.LeftKelvin.Active || device.RightKelvin.Active {
-			fmt.Printf("Eight Sleep is ON\n")
-			if device.LeftKelvin.Active {
-				fmt.Printf("Left side: %s\n", device.LeftKelvin.CurrentActivity)
-				if device.LeftHeatingLevel < device.LeftTargetHeatingLevel {
-					fmt.Printf("Left side target heating to level %d from %d\n", device.LeftTargetHeatingLevel, device.LeftHeatingLevel)
-				} else if device.LeftHeatingLevel > device.LeftTargetHeatingLevel {
-					fmt.Printf("Left side target cooling to level %d from %d\n", device.LeftTargetHeatingLevel, device.LeftHeatingLevel)
-				}
-			}
-			if device.RightKelvin.Active {
-				fmt.Printf("Right side: %s\n", device.RightKelvin.CurrentActivity)
-				if device.RightHeatingLevel < device.RightTargetHeatingLevel {
-					fmt.Printf("Right side target heating to level %d from %d\n", device.RightTargetHeatingLevel, device.RightHeatingLevel)
-				} else if device.RightHeatingLevel > device.RightTargetHeatingLevel {
-					fmt.Printf("Right side target cooling to level %d from %d\n", device.RightTargetHeatingLevel, device.RightHeatingLevel)
-				}
+// SetUnit overrides the account's preferred temperature unit
+func (c *Client) SetUnit(unit UnitOfTemperature) {
+	c.mu.Lock()
+	c.unit = unit
+	c.mu.Unlock()
+}
+
+// Unit returns the preferred temperature unit, from SetUnit or the account's measurement system
+func (c *Client) Unit() UnitOfTemperature {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.unit != "" {
+		return c.unit
+	}
+	if c.me != nil && c.me.DisplaySettings.MeasurementSystem == "metric" {
+		return Celsius
+	}
+	return Fahrenheit
+}
+
+// Status fetches the current state of the user's pod
+func (c *Client) Status(ctx context.Context) (*PodStatus, error) {
+	device, err := c.fetchDevice(ctx, c.deviceID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	unit := c.Unit()
+	status := &PodStatus{
+		DeviceID:     device.ID,
+		Online:       device.Online,
+		HasWater:     device.HasWater,
+		NeedsPriming: device.NeedsPriming,
+		Priming:      device.Priming,
+		Unit:         unit,
+		Left: newSideStatus(Left, device.LeftKelvin, device.LeftHeatingLevel, device.LeftTargetHeatingLevel,
+			device.LeftUserID, device.AwaySides.LeftUserID != "", unit),
+		Right: newSideStatus(Right, device.RightKelvin, device.RightHeatingLevel, device.RightTargetHeatingLevel,
+			device.RightUserID, device.AwaySides.RightUserID != "", unit),
+	}
+
+	if ramps := c.RampRates(); ramps != nil {
+		ramps.Observe(status, time.Now())
+		for _, side := range []*SideStatus{&status.Left, &status.Right} {
+			if side.On && side.Direction != Steady {
+				side.ETA, _ = ramps.Estimate(status.DeviceID, side.Level, side.TargetLevel)
 			}
-		} else {
-			fmt.Printf("Eight Sleep is OFF\n")
 		}
 	}
+
+	return status, nil
+}
+
+// SetRampRates sets the ramp rates Status learns from and estimates ETAs with
+func (c *Client) SetRampRates(ramps *RampRates) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ramps = ramps
+}
+
+// RampRates returns the ramp rates set with SetRampRates
+func (c *Client) RampRates() *RampRates {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ramps
+}
+
+// EstimateRamp estimates how long the pod takes to move from one heating level to another, so
+// callers can start pre-conditioning early enough to reach a level by a given time
+func (c *Client) EstimateRamp(from, to int) (time.Duration, bool) {
+	ramps := c.RampRates()
+	if ramps == nil {
+		return 0, false
+	}
+	return ramps.Estimate(c.deviceID(), from, to)
+}
+
+// Side returns the side of the pod the user sleeps on
+func (c *Client) Side() Side {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.me != nil && Side(c.me.CurrentDevice.Side) == Right {
+		return Right
+	}
+	return Left
+}
+
+// WaitForTarget polls the status every interval until the side's level is within tolerance of its
+// target level. It returns ErrDeviceOffline if the pod goes offline, ErrSideOff if the side is off,
+// or the context's error once it is done.
+func (c *Client) WaitForTarget(ctx context.Context, side Side, tolerance int, interval time.Duration) (*PodStatus, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := c.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !status.Online {
+			return status, ErrDeviceOffline
+		}
+		s := status.Side(side)
+		if !s.On {
+			return status, ErrSideOff
+		}
+		if LevelsWithin(s.Level, s.TargetLevel, tolerance) {
+			return status, nil
+		}
+		log.Debug("Waiting for target", "side", side, "level", s.Level, "target", s.TargetLevel)
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func newSideStatus(side Side, kelvin Kelvin, level, target int, userID string, away bool, unit UnitOfTemperature) SideStatus {
+	direction := Steady
+	if level < target {
+		direction = Heating
+	} else if level > target {
+		direction = Cooling
+	}
+	return SideStatus{
+		Side:              side,
+		On:                kelvin.Active,
+		Activity:          kelvin.CurrentActivity,
+		Level:             level,
+		TargetLevel:       target,
+		Direction:         direction,
+		Temperature:       HeatingLevelToTemp(level, unit),
+		TargetTemperature: HeatingLevelToTemp(target, unit),
+		UserID:            userID,
+		Away:              away,
+	}
 }
 
 /* -------------------- internal helpers -------------------- */
 
+// currentLevel returns the current heating level of the user's side of the pod and whether it is on
+func (c *Client) currentLevel(state *TemperatureState) (int, bool, error) {
+	if len(state.Devices) == 0 {
+		return 0, false, fmt.Errorf("no devices found in temperature state")
+	}
+	device := state.Devices[0]
+	side := c.Side()
+	for _, d := range state.Devices {
+		if Side(d.Device.Side) == side {
+			device = d
+			break
+		}
+	}
+	return device.CurrentLevel, device.CurrentState.Type != "off", nil
+}
+
 func (c *Client) headers() http.Header {
 	h := http.Header{}
 	h.Set("Content-Type", "application/json")
@@ -324,6 +700,9 @@ func (c *Client) headers() http.Header {
 }
 
 func (c *Client) refreshToken(ctx context.Context) error {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
 	c.mu.RLock()
 	needsRefresh := c.token == nil || time.Until(c.token.Expiration) < time.Second*tokenRefreshBufferSec
 	c.mu.RUnlock()
@@ -343,7 +722,9 @@ func (c *Client) refreshToken(ctx context.Context) error {
 		ExpiresIn   float64 `json:"expires_in"`
 		UserID      string  `json:"userId"`
 	}
-	if err := c.doJSON(ctx, http.MethodPost, authURL, body, &res); err != nil {
+	err := c.do(ctx, http.MethodPost, authURL, body, &res)
+	c.observeTokenRefresh(err)
+	if err != nil {
 		return fmt.Errorf("failed to refresh token: %w", err)
 	}
 
@@ -366,181 +747,139 @@ func (c *Client) fetchProfile(ctx context.Context) error {
 		return fmt.Errorf("failed to fetch profile: %w", err)
 	}
 	c.mu.Lock()
-	for _, f := range data.User.Features {
-		if f == "cooling" {
-			c.isPod = true
-		}
-		if f == "elevation" {
-			c.hasBase = true
-		}
-	}
 	c.me = &data.User
 	c.mu.Unlock()
 	return nil
 }
 
 func (c *Client) fetchDevices(ctx context.Context) error {
-	for _, device := range c.me.Devices {
-		url := clientAPIURL + "/devices/" + device
-		var data struct {
-			Result Device `json:"result"`
-		}
-		if err := c.doJSON(ctx, http.MethodGet, url, nil, &data); err != nil {
-			return fmt.Errorf("failed to fetch device %s: %w", device, err)
-		}
-		c.mu.Lock()
-		c.devices = append(c.devices, data.Result)
-		c.mu.Unlock()
-	}
-	return nil
-}
+	c.mu.RLock()
+	ids := append([]string(nil), c.me.Devices...)
+	c.mu.RUnlock()
 
-func (c *Client) fetchTrends(ctx context.Context) error {
-	url, err := url.Parse(clientAPIURL + "/users/" + c.me.ID + "/trends")
-	if err != nil {
-		return fmt.Errorf("failed to parse trends URL: %w", err)
-	}
-	q := url.Query()
-	q.Add("tz", c.devices[0].Timezone)
-	q.Add("from", time.Now().AddDate(0, 0, -1).Format(time.DateOnly))
-	q.Add("to", time.Now().Format(time.DateOnly))
-	q.Add("include-main", "false")
-	q.Add("include-all-sessions", "true")
-	q.Add("model-version", "v2")
-	url.RawQuery = q.Encode()
-	// var data struct {
-	// 	Days          []any  `json:"days"`
-	// 	ModelVersion  string `json:"modelVersion"`
-	// 	SfsCalculator string `json:"sfsCalculator"`
-	// }
-	var data map[string]any
-	if err := c.doJSON(ctx, http.MethodGet, url.String(), nil, &data); err != nil {
-		return fmt.Errorf("failed to fetch trends: %w", err)
+	var devices []Device
+	for _, id := range ids {
+		device, err := c.fetchDevice(ctx, id)
+		if err != nil {
+			return err
+		}
+		devices = append(devices, *device)
 	}
 	c.mu.Lock()
-	log.Info("TRENDS")
-	if err := prettyPrint(data); err != nil {
-		return fmt.Errorf("failed to pretty print response: %w", err)
-	}
+	c.devices = devices
 	c.mu.Unlock()
 	return nil
 }
 
-func (c *Client) fetchIntervals(ctx context.Context) error {
-	url, err := url.Parse(clientAPIURL + "/users/" + c.me.ID + "/intervals")
-	if err != nil {
-		return fmt.Errorf("failed to parse intervals URL: %w", err)
-	}
-	// var data struct {
-	// 	Settings struct {
-	// 		Routines     []any `json:"routines"`
-	// 		OneOffAlarms []any `json:"oneOffAlarms"`
-	// 	} `json:"settings"`
-	// 	State struct {
-	// 		Status    string `json:"status"`
-	// 		NextAlarm struct {
-	// 			NextTimestamp string `json:"nextTimestamp"`
-	// 		} `json:"nextAlarm"`
-	// 	} `json:"state"`
-	// }
-	var data map[string]any
-	if err := c.doJSON(ctx, http.MethodGet, url.String(), nil, &data); err != nil {
-		return fmt.Errorf("failed to fetch intervals: %w", err)
+func (c *Client) fetchDevice(ctx context.Context, id string) (*Device, error) {
+	url := clientAPIURL + "/devices/" + id
+	var data struct {
+		Result Device `json:"result"`
 	}
-	c.mu.Lock()
-	log.Info("INTERVALS")
-	if err := prettyPrint(data); err != nil {
-		return fmt.Errorf("failed to pretty print response: %w", err)
+	if err := c.doJSON(ctx, http.MethodGet, url, nil, &data); err != nil {
+		return nil, fmt.Errorf("failed to fetch device %s: %w", id, err)
 	}
-	c.mu.Unlock()
-	return nil
+	return &data.Result, nil
 }
 
-func (c *Client) fetchRoutines(ctx context.Context) error {
-	url, err := url.Parse(appAPIURL + "/v2/users/" + c.me.ID + "/routines")
-	if err != nil {
-		return fmt.Errorf("failed to parse routines URL: %w", err)
-	}
-	// var data struct {
-	// 	Settings struct {
-	// 		Routines     []any `json:"routines"`
-	// 		OneOffAlarms []any `json:"oneOffAlarms"`
-	// 	} `json:"settings"`
-	// 	State struct {
-	// 		Status    string `json:"status"`
-	// 		NextAlarm struct {
-	// 			NextTimestamp string `json:"nextTimestamp"`
-	// 		} `json:"nextAlarm"`
-	// 	} `json:"state"`
-	// }
-	var data map[string]any
-	if err := c.doJSON(ctx, http.MethodGet, url.String(), nil, &data); err != nil {
-		return fmt.Errorf("failed to fetch routines: %w", err)
+// deviceLocation returns the timezone of the user's device, falling back to the local timezone.
+// The caller must hold c.mu.
+func (c *Client) deviceLocation() *time.Location {
+	for _, name := range c.deviceTimezones() {
+		if name == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+		log.Debug("Ignoring unknown device timezone", "tz", name)
 	}
-	c.mu.Lock()
-	log.Info("ROUTINES")
-	if err := prettyPrint(data); err != nil {
-		return fmt.Errorf("failed to pretty print response: %w", err)
+	return time.Local
+}
+
+// deviceTimezones returns the timezone names reported for the user's current device and devices
+func (c *Client) deviceTimezones() []string {
+	names := []string{c.me.CurrentDevice.TimeZone}
+	for _, device := range c.devices {
+		names = append(names, device.Timezone)
 	}
-	c.mu.Unlock()
-	return nil
+	return names
 }
 
-func (c *Client) fetchHealthSurveyTestDrive(ctx context.Context) error {
-	url, err := url.Parse(appAPIURL + "/v1/health-survey/test-drive")
-	if err != nil {
-		return fmt.Errorf("failed to parse health survey test drive URL: %w", err)
+// deviceTimezone returns the first timezone name reported by the devices, even one this system
+// has no zone data for, since the API knows it
+func (c *Client) deviceTimezone() string {
+	for _, name := range c.deviceTimezones() {
+		if name != "" {
+			return name
+		}
 	}
-	var data map[string]any
-	if err := c.doJSON(ctx, http.MethodGet, url.String(), nil, &data); err != nil {
-		return fmt.Errorf("failed to fetch routines: %w", err)
+	return ""
+}
+
+// timezoneName returns the IANA name of the timezone for the API, empty when none is known. The
+// name of the time.Local fallback is "Local", which the API does not understand.
+func (c *Client) timezoneName() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.tzName != "" {
+		return c.tzName
 	}
-	c.mu.Lock()
-	log.Info("HEALTH SURVEY TEST DRIVE")
-	if err := prettyPrint(data); err != nil {
-		return fmt.Errorf("failed to pretty print response: %w", err)
+	if c.tz != nil && c.tz != time.Local {
+		return c.tz.String()
 	}
-	c.mu.Unlock()
-	return nil
+	return ""
 }
 
-func (c *Client) fetchSubscriptions(ctx context.Context) error {
-	url, err := url.Parse(appAPIURL + "/v3/users/" + c.me.ID + "/subscriptions")
-	if err != nil {
-		return fmt.Errorf("failed to parse subscriptions URL: %w", err)
+// userID returns the ID of the logged in user
+func (c *Client) userID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.me.ID
+}
+
+// deviceID returns the ID of the user's current device
+func (c *Client) deviceID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.me.CurrentDevice.ID != "" {
+		return c.me.CurrentDevice.ID
 	}
-	var data map[string]any
-	if err := c.doJSON(ctx, http.MethodGet, url.String(), nil, &data); err != nil {
-		return fmt.Errorf("failed to fetch subscriptions: %w", err)
+	if len(c.me.Devices) > 0 {
+		return c.me.Devices[0]
 	}
+	return ""
+}
+
+// invalidateToken forces the next request to log in again
+func (c *Client) invalidateToken() {
 	c.mu.Lock()
-	log.Info("SUBSCRIPTIONS")
-	if err := prettyPrint(data); err != nil {
-		return fmt.Errorf("failed to pretty print response: %w", err)
-	}
+	c.token = nil
 	c.mu.Unlock()
-	return nil
 }
 
-func (c *Client) fetchAutopilotDetails(ctx context.Context) error {
-	url, err := url.Parse(appAPIURL + "/v1/users/" + c.me.ID + "/autopilotDetails")
-	if err != nil {
-		return fmt.Errorf("failed to parse autopilot details URL: %w", err)
-	}
-	var data map[string]any
-	log.Info("AUTOPILOT DETAILS")
-	if err := c.doJSON(ctx, http.MethodGet, url.String(), nil, &data); err != nil {
-		return fmt.Errorf("failed to fetch autopilot details: %w", err)
+// doJSON sends an authenticated request. The token is refreshed before it expires, and if the
+// API rejects it anyway the client logs in again and retries the request once.
+func (c *Client) doJSON(ctx context.Context, method, url string, payload any, out any) error {
+	if err := c.refreshToken(ctx); err != nil {
+		return err
 	}
-	c.mu.Lock()
-	if err := prettyPrint(data); err != nil {
-		return fmt.Errorf("failed to pretty print response: %w", err)
+
+	err := c.do(ctx, method, url, payload, out)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+		log.Debug("Access token rejected, logging in again")
+		c.invalidateToken()
+		if err := c.refreshToken(ctx); err != nil {
+			return err
+		}
+		return c.do(ctx, method, url, payload, out)
 	}
-	c.mu.Unlock()
-	return nil
+	return err
 }
 
-func (c *Client) doJSON(ctx context.Context, method, url string, payload any, out any) error {
+// do sends a single request and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, url string, payload any, out any) error {
 	var body *bytes.Reader
 
 	if payload != nil {
@@ -559,17 +898,16 @@ func (c *Client) doJSON(ctx context.Context, method, url string, payload any, ou
 	}
 	req.Header = c.headers()
 
+	start := time.Now()
 	res, err := c.http.Do(req)
 	if err != nil {
+		c.observeRequest(req, 0, start)
 		return fmt.Errorf("failed to execute %s request: %w", method, err)
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode >= 300 {
-		return fmt.Errorf("HTTP %d: %s", res.StatusCode, res.Status)
-	}
-
 	data, err := io.ReadAll(res.Body)
+	c.observeRequest(req, res.StatusCode, start)
 	if err != nil {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
@@ -587,16 +925,12 @@ func (c *Client) doJSON(ctx context.Context, method, url string, payload any, ou
 
 	log.Debugf("HTTP %s %s: %d\n%s", method, url, res.StatusCode, string(data))
 
-	return json.NewDecoder(bytes.NewReader(data)).Decode(out)
-}
-
-func prettyPrint(data any) error {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal json: %v", err)
+	if res.StatusCode >= 300 {
+		return &APIError{StatusCode: res.StatusCode, Status: res.Status, Body: data}
 	}
-	if err := quick.Highlight(os.Stdout, string(jsonData)+"\n", "json", "terminal256", "nord"); err != nil {
-		return fmt.Errorf("failed to highlight json: %v", err)
+
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
 	}
-	return nil
+	return json.NewDecoder(bytes.NewReader(data)).Decode(out)
 }
@@ -0,0 +1,37 @@
+package eightsleep
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimezoneName(t *testing.T) {
+	configured, err := NewClient("", "", "America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	local, err := NewClient("", "", "Local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := &Client{me: &Profile{}, devices: []Device{{Timezone: ""}, {Timezone: "Mars/Olympus_Mons"}}}
+	unknown.tz = unknown.deviceLocation()
+	unknown.tzName = unknown.deviceTimezone()
+
+	tests := []struct {
+		name   string
+		client *Client
+		want   string
+	}{
+		{name: "configured", client: configured, want: "America/New_York"},
+		{name: "local", client: local},
+		{name: "not started", client: &Client{}},
+		{name: "zone only", client: &Client{tz: time.UTC}, want: "UTC"},
+		{name: "device zone without zone data", client: unknown, want: "Mars/Olympus_Mons"},
+	}
+	for _, tt := range tests {
+		if got := tt.client.timezoneName(); got != tt.want {
+			t.Errorf("%s: timezoneName() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package eightsleep
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestInfo describes an HTTP request made by the client
+type RequestInfo struct {
+	Method string
+	// Host is the API host the request was sent to, e.g. client-api.8slp.net
+	Host string
+	// StatusCode is the response status, zero when no response was received
+	StatusCode int
+	Duration   time.Duration
+	// Context is the context the request was made with, e.g. to tell callers apart
+	Context context.Context
+}
+
+// Hooks observe the client, e.g. to export metrics. They are called synchronously from the
+// goroutine making the request, so they must be fast and safe for concurrent use.
+type Hooks struct {
+	// Request is called after every HTTP request, including retries and logins
+	Request func(RequestInfo)
+	// TokenRefresh is called after every login with its error, nil when it succeeded
+	TokenRefresh func(error)
+}
+
+// SetHooks sets the hooks observing the client. Set them before Start to observe the first login.
+func (c *Client) SetHooks(hooks Hooks) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = hooks
+}
+
+func (c *Client) observeRequest(req *http.Request, statusCode int, start time.Time) {
+	c.mu.RLock()
+	hook := c.hooks.Request
+	c.mu.RUnlock()
+	if hook != nil {
+		hook(RequestInfo{
+			Method:     req.Method,
+			Host:       req.URL.Host,
+			StatusCode: statusCode,
+			Duration:   time.Since(start),
+			Context:    req.Context(),
+		})
+	}
+}
+
+func (c *Client) observeTokenRefresh(err error) {
+	c.mu.RLock()
+	hook := c.hooks.TokenRefresh
+	c.mu.RUnlock()
+	if hook != nil {
+		hook(err)
+	}
+}
@@ -0,0 +1,167 @@
+package eightsleep
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// rampSmoothing is the weight of the newest observation in the moving average of a ramp rate
+	rampSmoothing = 0.3
+	// rampMaxGap is the longest gap between two polls that is still used to measure a ramp rate,
+	// beyond it the side may have reached its target in between and the rate would be understated
+	rampMaxGap = 15 * time.Minute
+)
+
+// RampRate is the observed speed a device moves between heating levels in one direction
+type RampRate struct {
+	LevelsPerMinute float64   `json:"levelsPerMinute"`
+	Samples         int       `json:"samples"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// RampSample is the last level seen for a side of a device
+type RampSample struct {
+	At     time.Time `json:"at"`
+	Level  int       `json:"level"`
+	Target int       `json:"target"`
+}
+
+// RampRates learns how fast devices heat and cool from consecutive status polls. It is safe for
+// concurrent use and can be persisted as JSON between runs.
+type RampRates struct {
+	mu sync.Mutex
+
+	Rates   map[string]RampRate   `json:"rates"`
+	Samples map[string]RampSample `json:"samples"`
+
+	dirty bool
+}
+
+// NewRampRates returns an empty set of ramp rates
+func NewRampRates() *RampRates {
+	return &RampRates{
+		Rates:   make(map[string]RampRate),
+		Samples: make(map[string]RampSample),
+	}
+}
+
+func rateKey(deviceID string, direction Direction) string {
+	return deviceID + "/" + string(direction)
+}
+
+func sampleKey(deviceID string, side Side) string {
+	return deviceID + "/" + string(side)
+}
+
+// Observe records the levels in status and updates the ramp rates of sides that moved towards an
+// unchanged target since the previous observation
+func (r *RampRates) Observe(status *PodStatus, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Rates == nil {
+		r.Rates = make(map[string]RampRate)
+	}
+	if r.Samples == nil {
+		r.Samples = make(map[string]RampSample)
+	}
+
+	for _, side := range []*SideStatus{&status.Left, &status.Right} {
+		key := sampleKey(status.DeviceID, side.Side)
+		prev, ok := r.Samples[key]
+		if !side.On {
+			if ok {
+				delete(r.Samples, key)
+				r.dirty = true
+			}
+			continue
+		}
+		r.Samples[key] = RampSample{At: now, Level: side.Level, Target: side.TargetLevel}
+		r.dirty = true
+
+		if !ok || prev.Target != side.TargetLevel || side.Direction == Steady {
+			continue
+		}
+		elapsed := now.Sub(prev.At)
+		if elapsed <= 0 || elapsed > rampMaxGap {
+			continue
+		}
+		moved := side.Level - prev.Level
+		if side.Direction == Cooling {
+			moved = -moved
+		}
+		if moved <= 0 {
+			continue
+		}
+
+		observed := float64(moved) / elapsed.Minutes()
+		rate := r.Rates[rateKey(status.DeviceID, side.Direction)]
+		if rate.Samples == 0 {
+			rate.LevelsPerMinute = observed
+		} else {
+			rate.LevelsPerMinute = rampSmoothing*observed + (1-rampSmoothing)*rate.LevelsPerMinute
+		}
+		rate.Samples++
+		rate.UpdatedAt = now
+		r.Rates[rateKey(status.DeviceID, side.Direction)] = rate
+	}
+}
+
+// Rate returns the observed ramp rate of a device in a direction
+func (r *RampRates) Rate(deviceID string, direction Direction) (RampRate, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rate, ok := r.Rates[rateKey(deviceID, direction)]
+	return rate, ok && rate.LevelsPerMinute > 0
+}
+
+// Estimate returns how long a device is expected to take to move from one heating level to
+// another, or false if its rate in that direction has not been observed yet
+func (r *RampRates) Estimate(deviceID string, from, to int) (time.Duration, bool) {
+	if from == to {
+		return 0, true
+	}
+	direction := Heating
+	if to < from {
+		direction = Cooling
+	}
+	rate, ok := r.Rate(deviceID, direction)
+	if !ok {
+		return 0, false
+	}
+	minutes := float64(abs(to-from)) / rate.LevelsPerMinute
+	return time.Duration(minutes * float64(time.Minute)).Round(time.Second), true
+}
+
+// Dirty reports whether the rates changed since they were last marked clean
+func (r *RampRates) Dirty() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dirty
+}
+
+// MarkClean marks the rates as persisted
+func (r *RampRates) MarkClean() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dirty = false
+}
+
+// String returns a summary of the observed rates
+func (r RampRate) String() string {
+	return fmt.Sprintf("%.1f levels/min (%d samples)", r.LevelsPerMinute, r.Samples)
+}
+
+// MarshalJSON encodes the rates and samples while holding the lock
+func (r *RampRates) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return json.Marshal(struct {
+		Rates   map[string]RampRate   `json:"rates"`
+		Samples map[string]RampSample `json:"samples"`
+	}{r.Rates, r.Samples})
+}
@@ -0,0 +1,136 @@
+package eightsleep
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRampRatesObserve(t *testing.T) {
+	start := time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)
+	side := func(on bool, level, target int, direction Direction) SideStatus {
+		return SideStatus{Side: Left, On: on, Level: level, TargetLevel: target, Direction: direction}
+	}
+	type poll struct {
+		after time.Duration
+		side  SideStatus
+	}
+	tests := []struct {
+		name      string
+		polls     []poll
+		direction Direction
+		want      float64 // levels per minute, 0 when no rate should be learned
+		samples   int
+	}{
+		{
+			name: "heating",
+			polls: []poll{
+				{0, side(true, 0, 50, Heating)},
+				{5 * time.Minute, side(true, 10, 50, Heating)},
+			},
+			direction: Heating,
+			want:      2,
+			samples:   1,
+		},
+		{
+			name: "cooling",
+			polls: []poll{
+				{0, side(true, 0, -50, Cooling)},
+				{4 * time.Minute, side(true, -12, -50, Cooling)},
+			},
+			direction: Cooling,
+			want:      3,
+			samples:   1,
+		},
+		{
+			name: "moving average",
+			polls: []poll{
+				{0, side(true, 0, 50, Heating)},
+				{5 * time.Minute, side(true, 10, 50, Heating)},
+				{10 * time.Minute, side(true, 30, 50, Heating)},
+			},
+			direction: Heating,
+			want:      rampSmoothing*4 + (1-rampSmoothing)*2,
+			samples:   2,
+		},
+		{
+			name: "target changed",
+			polls: []poll{
+				{0, side(true, 0, 50, Heating)},
+				{5 * time.Minute, side(true, 10, 60, Heating)},
+			},
+			direction: Heating,
+		},
+		{
+			name: "gap too long",
+			polls: []poll{
+				{0, side(true, 0, 50, Heating)},
+				{rampMaxGap + time.Minute, side(true, 10, 50, Heating)},
+			},
+			direction: Heating,
+		},
+		{
+			name: "steady",
+			polls: []poll{
+				{0, side(true, 50, 50, Steady)},
+				{5 * time.Minute, side(true, 50, 50, Steady)},
+			},
+			direction: Heating,
+		},
+		{
+			name: "moved away from target",
+			polls: []poll{
+				{0, side(true, 10, 50, Heating)},
+				{5 * time.Minute, side(true, 5, 50, Heating)},
+			},
+			direction: Heating,
+		},
+		{
+			name: "turned off in between",
+			polls: []poll{
+				{0, side(true, 0, 50, Heating)},
+				{2 * time.Minute, side(false, 0, 0, Steady)},
+				{5 * time.Minute, side(true, 10, 50, Heating)},
+			},
+			direction: Heating,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates := NewRampRates()
+			for _, p := range tt.polls {
+				rates.Observe(&PodStatus{DeviceID: "pod", Left: p.side, Right: SideStatus{Side: Right}}, start.Add(p.after))
+			}
+			rate, ok := rates.Rate("pod", tt.direction)
+			if tt.want == 0 {
+				if ok {
+					t.Errorf("Rate() = %+v, want none", rate)
+				}
+				return
+			}
+			if !ok || math.Abs(rate.LevelsPerMinute-tt.want) > 1e-9 || rate.Samples != tt.samples {
+				t.Errorf("Rate() = %+v, %v, want %.2f levels/min from %d samples", rate, ok, tt.want, tt.samples)
+			}
+		})
+	}
+}
+
+func TestRampRatesEstimate(t *testing.T) {
+	rates := NewRampRates()
+	rates.Rates[rateKey("pod", Heating)] = RampRate{LevelsPerMinute: 2, Samples: 1}
+	tests := []struct {
+		from, to int
+		want     time.Duration
+		ok       bool
+	}{
+		{from: 0, to: 20, want: 10 * time.Minute, ok: true},
+		{from: 20, to: 20, want: 0, ok: true},
+		{from: 20, to: 0, ok: false}, // cooling rate not observed
+	}
+	for _, tt := range tests {
+		got, ok := rates.Estimate("pod", tt.from, tt.to)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Estimate(%d, %d) = %v, %v, want %v, %v", tt.from, tt.to, got, ok, tt.want, tt.ok)
+		}
+	}
+}
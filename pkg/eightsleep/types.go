@@ -1,6 +1,11 @@
 package eightsleep
 
-import "time"
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Token struct {
 	Bearer     string
@@ -10,6 +15,21 @@ type Token struct {
 
 type UnitOfTemperature string
 
+type Side string
+
+const (
+	Left  Side = "left"
+	Right Side = "right"
+)
+
+type Direction string
+
+const (
+	Heating Direction = "heating"
+	Cooling Direction = "cooling"
+	Steady  Direction = "steady"
+)
+
 const (
 	Celsius    UnitOfTemperature = "c"
 	Fahrenheit UnitOfTemperature = "f"
@@ -64,6 +84,7 @@ type Profile struct {
 type Device struct {
 	ID                     string `json:"deviceId"`
 	OwnerID                string `json:"ownerId"`
+	LeftUserID             string `json:"leftUserId"`
 	LeftHeatingLevel       int    `json:"leftHeatingLevel"`
 	LeftTargetHeatingLevel int    `json:"leftTargetHeatingLevel"`
 	LeftNowHeating         bool   `json:"leftNowHeating"`
@@ -136,50 +157,21 @@ type Device struct {
 		EightMattress any `json:"eightMattress"`
 		Brand         any `json:"brand"`
 	} `json:"mattressInfo"`
-	FirmwareCommit          string    `json:"firmwareCommit"`
-	FirmwareVersion         string    `json:"firmwareVersion"`
-	FirmwareUpdated         bool      `json:"firmwareUpdated"`
-	FirmwareUpdating        bool      `json:"firmwareUpdating"`
-	LastFirmwareUpdateStart time.Time `json:"lastFirmwareUpdateStart"`
-	LastHeard               time.Time `json:"lastHeard"`
-	Online                  bool      `json:"online"`
-	EncasementType          any       `json:"encasementType"`
-	LeftKelvin              struct {
-		TargetLevels       []int  `json:"targetLevels"`
-		ScheduleProfiles   []any  `json:"scheduleProfiles"`
-		Alarms             []any  `json:"alarms"`
-		Level              int    `json:"level"`
-		CurrentTargetLevel int    `json:"currentTargetLevel"`
-		Active             bool   `json:"active"`
-		CurrentActivity    string `json:"currentActivity"`
-	} `json:"leftKelvin"`
-	RightKelvin struct {
-		TargetLevels     []int `json:"targetLevels"`
-		Alarms           []any `json:"alarms"`
-		ScheduleProfiles []struct {
-			Enabled        bool   `json:"enabled"`
-			StartLocalTime string `json:"startLocalTime"`
-			WeekDays       struct {
-				Monday    bool `json:"monday"`
-				Tuesday   bool `json:"tuesday"`
-				Wednesday bool `json:"wednesday"`
-				Thursday  bool `json:"thursday"`
-				Friday    bool `json:"friday"`
-				Saturday  bool `json:"saturday"`
-				Sunday    bool `json:"sunday"`
-			} `json:"weekDays"`
-		} `json:"scheduleProfiles"`
-		Phases             []any  `json:"phases"`
-		Level              int    `json:"level"`
-		CurrentTargetLevel int    `json:"currentTargetLevel"`
-		Active             bool   `json:"active"`
-		CurrentActivity    string `json:"currentActivity"`
-	} `json:"rightKelvin"`
-	Features                   []string `json:"features"`
-	LeftUserInvitationPending  bool     `json:"leftUserInvitationPending"`
-	RightUserInvitationPending bool     `json:"rightUserInvitationPending"`
-	ModelString                string   `json:"modelString"`
-	HubSerial                  string   `json:"hubSerial"`
+	FirmwareCommit             string    `json:"firmwareCommit"`
+	FirmwareVersion            string    `json:"firmwareVersion"`
+	FirmwareUpdated            bool      `json:"firmwareUpdated"`
+	FirmwareUpdating           bool      `json:"firmwareUpdating"`
+	LastFirmwareUpdateStart    time.Time `json:"lastFirmwareUpdateStart"`
+	LastHeard                  time.Time `json:"lastHeard"`
+	Online                     bool      `json:"online"`
+	EncasementType             any       `json:"encasementType"`
+	LeftKelvin                 Kelvin    `json:"leftKelvin"`
+	RightKelvin                Kelvin    `json:"rightKelvin"`
+	Features                   []string  `json:"features"`
+	LeftUserInvitationPending  bool      `json:"leftUserInvitationPending"`
+	RightUserInvitationPending bool      `json:"rightUserInvitationPending"`
+	ModelString                string    `json:"modelString"`
+	HubSerial                  string    `json:"hubSerial"`
 	WifiInfo                   struct {
 		SignalStrength int       `json:"signalStrength"`
 		Ssid           string    `json:"ssid"`
@@ -196,6 +188,31 @@ type Device struct {
 	Deactivated            any       `json:"deactivated"`
 }
 
+type Kelvin struct {
+	TargetLevels       []int                   `json:"targetLevels"`
+	Alarms             []any                   `json:"alarms"`
+	ScheduleProfiles   []KelvinScheduleProfile `json:"scheduleProfiles"`
+	Phases             []any                   `json:"phases"`
+	Level              int                     `json:"level"`
+	CurrentTargetLevel int                     `json:"currentTargetLevel"`
+	Active             bool                    `json:"active"`
+	CurrentActivity    string                  `json:"currentActivity"`
+}
+
+type KelvinScheduleProfile struct {
+	Enabled        bool   `json:"enabled"`
+	StartLocalTime string `json:"startLocalTime"`
+	WeekDays       struct {
+		Monday    bool `json:"monday"`
+		Tuesday   bool `json:"tuesday"`
+		Wednesday bool `json:"wednesday"`
+		Thursday  bool `json:"thursday"`
+		Friday    bool `json:"friday"`
+		Saturday  bool `json:"saturday"`
+		Sunday    bool `json:"sunday"`
+	} `json:"weekDays"`
+}
+
 type TemperatureState struct {
 	Devices []struct {
 		Device struct {
@@ -227,3 +244,286 @@ type TemperatureState struct {
 		FinalSleepLevel   int    `json:"finalSleepLevel"`
 	} `json:"temperatureSettings"`
 }
+
+// Timestamp is a time decoded leniently from fields whose format is not documented.
+// Empty, null and unrecognized values decode to the zero time instead of failing the whole response.
+type Timestamp struct {
+	time.Time
+}
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	time.DateTime,
+	time.DateOnly,
+}
+
+// UnmarshalJSON accepts RFC 3339 and date strings as well as unix seconds or milliseconds
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	t.Time = time.Time{}
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" || raw == `""` {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = raw
+	}
+	s = strings.TrimSpace(s)
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n > 0 {
+		if n > 1e12 {
+			t.Time = time.UnixMilli(n)
+		} else {
+			t.Time = time.Unix(n, 0)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the zero time as null and anything else as RFC 3339
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time)
+}
+
+type AutopilotDetails struct {
+	Enabled     bool                  `json:"autopilotEnabled"`
+	LastUpdated Timestamp             `json:"lastUpdated"`
+	Adjustments []AutopilotAdjustment `json:"adjustments"`
+}
+
+type AutopilotAdjustment struct {
+	Timestamp     Timestamp `json:"timestamp"`
+	Stage         string    `json:"stage"`
+	PreviousLevel int       `json:"previousLevel"`
+	NewLevel      int       `json:"newLevel"`
+	Reason        string    `json:"reason"`
+}
+
+type Subscriptions struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+type Subscription struct {
+	ID             string    `json:"id"`
+	Plan           string    `json:"plan"`
+	Status         string    `json:"status"`
+	AutoRenew      bool      `json:"autoRenew"`
+	RenewalDate    Timestamp `json:"renewalDate"`
+	ExpirationDate Timestamp `json:"expirationDate"`
+	Features       []string  `json:"features"`
+}
+
+// Current returns the subscription in effect: the first active one, otherwise the one that ends
+// last. Accounts keep old cancelled and expired entries next to it.
+func (s Subscriptions) Current() (Subscription, bool) {
+	var current Subscription
+	found := false
+	for _, sub := range s.Subscriptions {
+		switch {
+		case sub.Active():
+			return sub, true
+		case !found, sub.EndsAt().After(current.EndsAt()):
+			current, found = sub, true
+		}
+	}
+	return current, found
+}
+
+// Active reports whether the subscription is currently in good standing
+func (s Subscription) Active() bool {
+	switch strings.ToLower(s.Status) {
+	case "active", "trialing", "trial":
+		return true
+	}
+	return false
+}
+
+// EndsAt returns when the subscription stops unless it is renewed (zero if it auto-renews)
+func (s Subscription) EndsAt() time.Time {
+	if !s.ExpirationDate.IsZero() {
+		return s.ExpirationDate.Time
+	}
+	if !s.AutoRenew {
+		return s.RenewalDate.Time
+	}
+	return time.Time{}
+}
+
+// Lapsing reports whether the subscription is inactive or will end within the given window. One
+// that has already ended is no longer about to lapse.
+func (s Subscription) Lapsing(within time.Duration) bool {
+	end := s.EndsAt()
+	if !end.IsZero() && end.Before(time.Now()) {
+		return false
+	}
+	if !s.Active() {
+		return true
+	}
+	return !end.IsZero() && time.Until(end) < within
+}
+
+// PodStatus is a snapshot of the pod and both of its sides
+type PodStatus struct {
+	DeviceID     string            `json:"deviceId"`
+	Online       bool              `json:"online"`
+	HasWater     bool              `json:"hasWater"`
+	NeedsPriming bool              `json:"needsPriming"`
+	Priming      bool              `json:"priming"`
+	Unit         UnitOfTemperature `json:"unit"`
+	Left         SideStatus        `json:"left"`
+	Right        SideStatus        `json:"right"`
+}
+
+// On reports whether either side of the pod is active
+func (s PodStatus) On() bool {
+	return s.Left.On || s.Right.On
+}
+
+// Side returns the status of the given side
+func (s *PodStatus) Side(side Side) *SideStatus {
+	if side == Right {
+		return &s.Right
+	}
+	return &s.Left
+}
+
+type SideStatus struct {
+	Side              Side      `json:"side"`
+	On                bool      `json:"on"`
+	Activity          string    `json:"activity"`
+	Level             int       `json:"level"`
+	TargetLevel       int       `json:"targetLevel"`
+	Direction         Direction `json:"direction"`
+	Temperature       int       `json:"temperature"`
+	TargetTemperature int       `json:"targetTemperature"`
+	UserID            string    `json:"userId,omitempty"`
+	Away              bool      `json:"away"`
+	// ETA is the estimated time until the target level is reached, zero when unknown or steady.
+	// It is encoded as whole seconds in etaSeconds.
+	ETA time.Duration `json:"-"`
+}
+
+// sideStatusJSON is the wire form of SideStatus
+type sideStatusJSON struct {
+	sideStatus
+	ETASeconds int64 `json:"etaSeconds,omitempty"`
+}
+
+type sideStatus SideStatus
+
+// MarshalJSON encodes the ETA as whole seconds
+func (s SideStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sideStatusJSON{sideStatus(s), int64(s.ETA.Round(time.Second) / time.Second)})
+}
+
+// UnmarshalJSON decodes the ETA from whole seconds
+func (s *SideStatus) UnmarshalJSON(data []byte) error {
+	var v sideStatusJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = SideStatus(v.sideStatus)
+	s.ETA = time.Duration(v.ETASeconds) * time.Second
+	return nil
+}
+
+type Routines struct {
+	Settings struct {
+		Routines     []Routine `json:"routines"`
+		OneOffAlarms []Alarm   `json:"oneOffAlarms"`
+	} `json:"settings"`
+	State struct {
+		Status    string `json:"status"`
+		NextAlarm struct {
+			NextTimestamp Timestamp `json:"nextTimestamp"`
+			AlarmID       string    `json:"alarmId"`
+		} `json:"nextAlarm"`
+	} `json:"state"`
+}
+
+type Routine struct {
+	ID      string   `json:"id"`
+	Days    []string `json:"days"`
+	Enabled bool     `json:"enabled"`
+	Bedtime struct {
+		Time      string `json:"time"`
+		DayOffset string `json:"dayOffset"`
+	} `json:"bedtime"`
+	Alarms []Alarm `json:"alarms"`
+}
+
+type Alarm struct {
+	AlarmID              string `json:"alarmId"`
+	Enabled              bool   `json:"enabled"`
+	DisabledIndividually bool   `json:"disabledIndividually"`
+	TimeWithOffset       struct {
+		Time      string `json:"time"`
+		DayOffset string `json:"dayOffset"`
+	} `json:"timeWithOffset"`
+	Settings struct {
+		Vibration struct {
+			Enabled    bool   `json:"enabled"`
+			PowerLevel int    `json:"powerLevel"`
+			Pattern    string `json:"pattern"`
+		} `json:"vibration"`
+		Thermal struct {
+			Enabled bool `json:"enabled"`
+			Level   int  `json:"level"`
+		} `json:"thermal"`
+	} `json:"settings"`
+	DismissedUntil Timestamp `json:"dismissedUntil"`
+	SnoozedUntil   Timestamp `json:"snoozedUntil"`
+}
+
+type Trends struct {
+	Days          []TrendDay `json:"days"`
+	ModelVersion  string     `json:"modelVersion"`
+	SfsCalculator string     `json:"sfsCalculator"`
+}
+
+type TrendDay struct {
+	Day                  string    `json:"day"`
+	Score                float64   `json:"score"`
+	Tnt                  float64   `json:"tnt"`
+	Processing           bool      `json:"processing"`
+	PresenceStart        Timestamp `json:"presenceStart"`
+	PresenceEnd          Timestamp `json:"presenceEnd"`
+	PresenceDuration     float64   `json:"presenceDuration"`
+	SleepDuration        float64   `json:"sleepDuration"`
+	LightDuration        float64   `json:"lightDuration"`
+	DeepDuration         float64   `json:"deepDuration"`
+	RemDuration          float64   `json:"remDuration"`
+	LatencyAsleepSeconds float64   `json:"latencyAsleepSeconds"`
+	LatencyOutSeconds    float64   `json:"latencyOutSeconds"`
+}
+
+type InfoSection string
+
+const (
+	InfoTrends        InfoSection = "trends"
+	InfoIntervals     InfoSection = "intervals"
+	InfoRoutines      InfoSection = "routines"
+	InfoHealthSurvey  InfoSection = "health-survey"
+	InfoSubscriptions InfoSection = "subscriptions"
+	InfoAutopilot     InfoSection = "autopilot"
+)
+
+var InfoSections = []InfoSection{InfoTrends, InfoIntervals, InfoRoutines, InfoHealthSurvey, InfoSubscriptions, InfoAutopilot}
+
+type InfoReport struct {
+	Trends        *Trends           `json:"trends,omitempty"`
+	Intervals     map[string]any    `json:"intervals,omitempty"`
+	Routines      *Routines         `json:"routines,omitempty"`
+	HealthSurvey  map[string]any    `json:"healthSurvey,omitempty"`
+	Subscriptions *Subscriptions    `json:"subscriptions,omitempty"`
+	Autopilot     *AutopilotDetails `json:"autopilot,omitempty"`
+}
@@ -0,0 +1,161 @@
+package eightsleep
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampUnmarshal(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{input: `"2024-03-01T22:30:00Z"`, want: time.Date(2024, 3, 1, 22, 30, 0, 0, time.UTC)},
+		{input: `"2024-03-01T22:30:00.123+01:00"`, want: time.Date(2024, 3, 1, 21, 30, 0, 123e6, time.UTC)},
+		{input: `"2024-03-01T22:30:00"`, want: time.Date(2024, 3, 1, 22, 30, 0, 0, time.UTC)},
+		{input: `"2024-03-01 22:30:00"`, want: time.Date(2024, 3, 1, 22, 30, 0, 0, time.UTC)},
+		{input: `"2024-03-01"`, want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{input: `1709332200`, want: time.Date(2024, 3, 1, 22, 30, 0, 0, time.UTC)},
+		{input: `1709332200000`, want: time.Date(2024, 3, 1, 22, 30, 0, 0, time.UTC)},
+		{input: `"1709332200"`, want: time.Date(2024, 3, 1, 22, 30, 0, 0, time.UTC)},
+		{input: `""`},
+		{input: `null`},
+		{input: `"soon"`},
+		{input: `0`},
+	}
+	for _, tt := range tests {
+		var got Timestamp
+		if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+			t.Errorf("Unmarshal(%s) error = %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.input, got.Time, tt.want)
+		}
+	}
+}
+
+func TestAutopilotDetailsLenientTimes(t *testing.T) {
+	data := `{"autopilotEnabled":true,"lastUpdated":"","adjustments":[{"timestamp":"not a time","stage":"deep","previousLevel":-10,"newLevel":-20}]}`
+	var details AutopilotDetails
+	if err := json.Unmarshal([]byte(data), &details); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	if !details.Enabled || !details.LastUpdated.IsZero() || len(details.Adjustments) != 1 || details.Adjustments[0].NewLevel != -20 {
+		t.Errorf("Unmarshal = %+v", details)
+	}
+}
+
+func TestRoutinesAndTrendsLenientTimes(t *testing.T) {
+	var routines Routines
+	data := `{"settings":{"routines":[{"id":"r","alarms":[{"alarmId":"a","dismissedUntil":"","snoozedUntil":1709332200}]}]},"state":{"nextAlarm":{"nextTimestamp":"2024-03-01 22:30:00"}}}`
+	if err := json.Unmarshal([]byte(data), &routines); err != nil {
+		t.Fatalf("Unmarshal routines error = %v", err)
+	}
+	want := time.Date(2024, 3, 1, 22, 30, 0, 0, time.UTC)
+	alarm := routines.Settings.Routines[0].Alarms[0]
+	if !routines.State.NextAlarm.NextTimestamp.Equal(want) || !alarm.DismissedUntil.IsZero() || !alarm.SnoozedUntil.Equal(want) {
+		t.Errorf("Unmarshal routines = %+v", routines)
+	}
+
+	var trends Trends
+	if err := json.Unmarshal([]byte(`{"days":[{"day":"2024-03-01","presenceStart":"","presenceEnd":1709332200000}]}`), &trends); err != nil {
+		t.Fatalf("Unmarshal trends error = %v", err)
+	}
+	if day := trends.Days[0]; !day.PresenceStart.IsZero() || !day.PresenceEnd.Equal(want) {
+		t.Errorf("Unmarshal trends = %+v", trends)
+	}
+}
+
+func TestSubscriptionLenientTimes(t *testing.T) {
+	data := `{"subscriptions":[{"id":"1","plan":"autopilot","status":"active","autoRenew":false,"renewalDate":"2099-01-02","expirationDate":""}]}`
+	var subs Subscriptions
+	if err := json.Unmarshal([]byte(data), &subs); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	if len(subs.Subscriptions) != 1 {
+		t.Fatalf("Unmarshal = %+v", subs)
+	}
+	sub := subs.Subscriptions[0]
+	if want := time.Date(2099, 1, 2, 0, 0, 0, 0, time.UTC); !sub.EndsAt().Equal(want) {
+		t.Errorf("EndsAt() = %v, want %v", sub.EndsAt(), want)
+	}
+	if sub.Lapsing(7 * 24 * time.Hour) {
+		t.Error("Lapsing() = true, want false")
+	}
+}
+
+func TestSideStatusJSON(t *testing.T) {
+	tests := []struct {
+		eta  time.Duration
+		want string
+	}{
+		{eta: 90 * time.Second, want: `"etaSeconds":90`},
+		{eta: 12*time.Minute + 400*time.Millisecond, want: `"etaSeconds":720`},
+		{eta: 0, want: ""},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(SideStatus{Side: Left, On: true, Level: 10, ETA: tt.eta})
+		if err != nil {
+			t.Fatalf("Marshal error = %v", err)
+		}
+		if tt.want != "" && !strings.Contains(string(data), tt.want) {
+			t.Errorf("Marshal(ETA %v) = %s, want %s", tt.eta, data, tt.want)
+		}
+		if tt.want == "" && strings.Contains(string(data), "eta") {
+			t.Errorf("Marshal(ETA %v) = %s, want no eta", tt.eta, data)
+		}
+		var got SideStatus
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal error = %v", err)
+		}
+		if want := tt.eta.Round(time.Second); got.ETA != want || got.Side != Left || !got.On || got.Level != 10 {
+			t.Errorf("round trip of ETA %v = %+v", tt.eta, got)
+		}
+	}
+}
+
+func TestSubscriptionLapsing(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		sub  Subscription
+		want bool
+	}{
+		{name: "auto renews", sub: Subscription{Status: "active", AutoRenew: true, RenewalDate: Timestamp{now.Add(48 * time.Hour)}}},
+		{name: "case insensitive", sub: Subscription{Status: "Active", AutoRenew: true}},
+		{name: "ends soon", sub: Subscription{Status: "active", ExpirationDate: Timestamp{now.Add(48 * time.Hour)}}, want: true},
+		{name: "ends later", sub: Subscription{Status: "trialing", ExpirationDate: Timestamp{now.AddDate(0, 2, 0)}}},
+		{name: "inactive", sub: Subscription{Status: "past_due", AutoRenew: true}, want: true},
+		{name: "already ended", sub: Subscription{Status: "expired", ExpirationDate: Timestamp{now.AddDate(-1, 0, 0)}}},
+	}
+	for _, tt := range tests {
+		if got := tt.sub.Lapsing(14 * 24 * time.Hour); got != tt.want {
+			t.Errorf("%s: Lapsing() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSubscriptionsCurrent(t *testing.T) {
+	now := time.Now()
+	old := Subscription{ID: "old", Status: "cancelled", ExpirationDate: Timestamp{now.AddDate(-2, 0, 0)}}
+	newer := Subscription{ID: "newer", Status: "expired", ExpirationDate: Timestamp{now.AddDate(-1, 0, 0)}}
+	active := Subscription{ID: "active", Status: "ACTIVE", AutoRenew: true}
+	tests := []struct {
+		name string
+		subs []Subscription
+		want string
+	}{
+		{name: "none"},
+		{name: "active", subs: []Subscription{old, active, newer}, want: "active"},
+		{name: "ended last", subs: []Subscription{old, newer}, want: "newer"},
+	}
+	for _, tt := range tests {
+		got, ok := Subscriptions{Subscriptions: tt.subs}.Current()
+		if ok != (tt.want != "") || got.ID != tt.want {
+			t.Errorf("%s: Current() = %q, %v, want %q", tt.name, got.ID, ok, tt.want)
+		}
+	}
+}
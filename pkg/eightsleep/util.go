@@ -1,5 +1,14 @@
 package eightsleep
 
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
 var rawToC = map[int]int{
 	-100: 13,
 	-97:  14,
@@ -97,36 +106,172 @@ var rawToF = map[int]int{
 	100:  111,
 }
 
-func heatingLevelToTemp(level int, unit UnitOfTemperature) int {
+// HeatingLevelToTemp converts a raw heating level (-100..100) to degrees in the given unit
+func HeatingLevelToTemp(level int, unit UnitOfTemperature) int {
 	m := rawToF
 	if unit == Celsius {
 		m = rawToC
 	}
 	closestKey := 0
 	minDiff := 1 << 31
-	for k, v := range m {
+	// sorted, so ties resolve the same way every time
+	for _, k := range slices.Sorted(maps.Keys(m)) {
 		if d := abs(k - level); d < minDiff {
-			minDiff, closestKey = d, v
+			minDiff, closestKey = d, m[k]
 		}
 	}
 	return closestKey
 }
 
-func tempToHeatingLevel(deg int, unit UnitOfTemperature) int {
+// TempToHeatingLevel converts degrees in the given unit to the closest raw heating level
+func TempToHeatingLevel(deg int, unit UnitOfTemperature) int {
 	m := rawToF
 	if unit == Celsius {
 		m = rawToC
 	}
 	closestKey := 0
 	minDiff := 1 << 31
-	for k, v := range m {
-		if d := abs(v - deg); d < minDiff {
+	// several levels map to some temperatures, sorted the lowest of them is used every time
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		if d := abs(m[k] - deg); d < minDiff {
 			minDiff, closestKey = d, k
 		}
 	}
 	return closestKey
 }
 
+// LevelToScale converts a raw heating level (-100..100) to the app's -10..+10 scale
+func LevelToScale(level int) int {
+	return int(math.Round(float64(level) / 10))
+}
+
+// ScaleToLevel converts a value on the app's -10..+10 scale to a raw heating level
+func ScaleToLevel(scale int) int {
+	return scale * 10
+}
+
+// LevelsWithin reports whether two raw heating levels differ by at most tolerance
+func LevelsWithin(a, b, tolerance int) bool {
+	return abs(a-b) <= tolerance
+}
+
+// FormatLevel renders a raw heating level in all three representations, e.g. "-30 (app -3, 73°F)"
+func FormatLevel(level int, unit UnitOfTemperature) string {
+	return fmt.Sprintf("%d (app %+d, %d°%s)",
+		level,
+		LevelToScale(level),
+		HeatingLevelToTemp(level, unit),
+		strings.ToUpper(string(unit)),
+	)
+}
+
+// ParseUnit parses a temperature unit such as "F", "celsius" or "metric"
+func ParseUnit(value string) (UnitOfTemperature, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "f", "fahrenheit", "imperial":
+		return Fahrenheit, nil
+	case "c", "celsius", "metric":
+		return Celsius, nil
+	}
+	return "", fmt.Errorf("invalid temperature unit '%s' (must be F or C)", value)
+}
+
+// ParseTemperature parses a temperature setting and returns the raw heating level.
+//
+// Accepted formats:
+//   - "68F" or "20C": absolute temperature in Fahrenheit or Celsius
+//   - "-3" or "+3": the app's -10..+10 relative scale
+//   - "68": absolute temperature in defaultUnit (any unitless value outside the app scale)
+func ParseTemperature(value string, defaultUnit UnitOfTemperature) (int, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return 0, fmt.Errorf("empty temperature")
+	}
+
+	unit := defaultUnit
+	switch {
+	case strings.HasSuffix(value, "C"):
+		unit = Celsius
+		value = value[:len(value)-1]
+	case strings.HasSuffix(value, "F"):
+		unit = Fahrenheit
+		value = value[:len(value)-1]
+	default:
+		scale, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid temperature format: %s (must be a number, optionally ending with C or F)", value)
+		}
+		if scale >= MIN_SCALE && scale <= MAX_SCALE {
+			return ScaleToLevel(scale), nil
+		}
+		if unit == "" {
+			return 0, fmt.Errorf("app scale value %d out of range (%d..%+d)", scale, MIN_SCALE, MAX_SCALE)
+		}
+	}
+
+	deg, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid temperature value: %s", value)
+	}
+	min, max := tempRange(unit)
+	if deg < min || deg > max {
+		return 0, fmt.Errorf("temperature %d%s out of range (%d..%d%s)", deg, strings.ToUpper(string(unit)), min, max, strings.ToUpper(string(unit)))
+	}
+	return TempToHeatingLevel(deg, unit), nil
+}
+
+// ParseTemperatureDelta parses a relative adjustment such as "+2F", "-1C" or "+1" (app scale).
+// The returned unit is empty when the delta is on the app's -10..+10 scale.
+func ParseTemperatureDelta(value string) (int, UnitOfTemperature, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return 0, "", fmt.Errorf("empty temperature adjustment")
+	}
+
+	var unit UnitOfTemperature
+	switch {
+	case strings.HasSuffix(value, "C"):
+		unit = Celsius
+		value = value[:len(value)-1]
+	case strings.HasSuffix(value, "F"):
+		unit = Fahrenheit
+		value = value[:len(value)-1]
+	}
+
+	delta, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid temperature adjustment: %s", value)
+	}
+	return delta, unit, nil
+}
+
+// AdjustLevel applies a delta in the given unit (or the app scale when unit is empty) to a raw
+// heating level, clamping the result to the supported range
+func AdjustLevel(level, delta int, unit UnitOfTemperature) int {
+	if unit == "" {
+		return clamp(level+ScaleToLevel(delta), MIN_LEVEL, MAX_LEVEL)
+	}
+	min, max := tempRange(unit)
+	return TempToHeatingLevel(clamp(HeatingLevelToTemp(level, unit)+delta, min, max), unit)
+}
+
+func tempRange(unit UnitOfTemperature) (int, int) {
+	if unit == Celsius {
+		return MIN_TEMP_C, MAX_TEMP_C
+	}
+	return MIN_TEMP_F, MAX_TEMP_F
+}
+
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
 func abs(n int) int {
 	if n < 0 {
 		return -n
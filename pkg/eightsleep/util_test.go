@@ -0,0 +1,156 @@
+package eightsleep
+
+import "testing"
+
+func TestParseTemperature(t *testing.T) {
+	tests := []struct {
+		value   string
+		unit    UnitOfTemperature
+		want    int
+		wantErr bool
+	}{
+		{value: "68F", unit: Celsius, want: -58},
+		{value: "68f", want: -58},
+		{value: " 20C ", unit: Fahrenheit, want: -58},
+		{value: "68", unit: Fahrenheit, want: -58},
+		{value: "20", unit: Celsius, want: -58},
+		{value: "-3", unit: Fahrenheit, want: -30},
+		{value: "+3", unit: Celsius, want: 30},
+		{value: "0", want: 0},
+		{value: "10", want: 100},
+		{value: "-10", want: -100},
+		{value: "55F", want: -100},
+		{value: "110F", want: 92},
+		{value: "68", wantErr: true}, // no unit and outside the app scale
+		{value: "11", unit: Celsius, wantErr: true},
+		{value: "120F", wantErr: true}, // out of range
+		{value: "12C", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "warm", wantErr: true},
+		{value: "F", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTemperature(tt.value, tt.unit)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTemperature(%q, %q) error = %v, wantErr %v", tt.value, tt.unit, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseTemperature(%q, %q) = %d, want %d", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestParseTemperatureDelta(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantDelta int
+		wantUnit  UnitOfTemperature
+		wantErr   bool
+	}{
+		{value: "+2F", wantDelta: 2, wantUnit: Fahrenheit},
+		{value: "-1c", wantDelta: -1, wantUnit: Celsius},
+		{value: "+1", wantDelta: 1},
+		{value: "2", wantDelta: 2},
+		{value: " -3 ", wantDelta: -3},
+		{value: "", wantErr: true},
+		{value: "F", wantErr: true},
+		{value: "+2K", wantErr: true},
+	}
+	for _, tt := range tests {
+		delta, unit, err := ParseTemperatureDelta(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTemperatureDelta(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (delta != tt.wantDelta || unit != tt.wantUnit) {
+			t.Errorf("ParseTemperatureDelta(%q) = %d, %q, want %d, %q", tt.value, delta, unit, tt.wantDelta, tt.wantUnit)
+		}
+	}
+}
+
+func TestScaleToLevel(t *testing.T) {
+	for _, tt := range []struct{ scale, level int }{
+		{-10, -100},
+		{-3, -30},
+		{0, 0},
+		{1, 10},
+		{10, 100},
+	} {
+		if got := ScaleToLevel(tt.scale); got != tt.level {
+			t.Errorf("ScaleToLevel(%d) = %d, want %d", tt.scale, got, tt.level)
+		}
+		if got := LevelToScale(tt.level); got != tt.scale {
+			t.Errorf("LevelToScale(%d) = %d, want %d", tt.level, got, tt.scale)
+		}
+	}
+	// levels between steps round to the nearest app scale value
+	for _, tt := range []struct{ level, scale int }{{-58, -6}, {-54, -5}, {4, 0}, {95, 10}} {
+		if got := LevelToScale(tt.level); got != tt.scale {
+			t.Errorf("LevelToScale(%d) = %d, want %d", tt.level, got, tt.scale)
+		}
+	}
+}
+
+func TestAdjustLevel(t *testing.T) {
+	tests := []struct {
+		level, delta int
+		unit         UnitOfTemperature
+		want         int
+	}{
+		{level: -30, delta: 1, want: -20},
+		{level: 95, delta: 2, want: 100},                    // clamped to the max level
+		{level: -58, delta: 2, unit: Fahrenheit, want: -49}, // 68F -> 70F
+		{level: -100, delta: -1, unit: Celsius, want: -100}, // already at the minimum
+	}
+	for _, tt := range tests {
+		if got := AdjustLevel(tt.level, tt.delta, tt.unit); got != tt.want {
+			t.Errorf("AdjustLevel(%d, %d, %q) = %d, want %d", tt.level, tt.delta, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestTempToHeatingLevel(t *testing.T) {
+	tests := []struct {
+		deg  int
+		unit UnitOfTemperature
+		want int
+	}{
+		{deg: 55, unit: Fahrenheit, want: -100},
+		{deg: 68, unit: Fahrenheit, want: -58},
+		// several levels map to these, the lowest is used
+		{deg: 77, unit: Fahrenheit, want: -18},
+		{deg: 86, unit: Fahrenheit, want: 16},
+		{deg: 107, unit: Fahrenheit, want: 80},
+		{deg: 20, unit: Celsius, want: -58},
+	}
+	for _, tt := range tests {
+		// repeated, map iteration order must not matter
+		for range 20 {
+			if got := TempToHeatingLevel(tt.deg, tt.unit); got != tt.want {
+				t.Fatalf("TempToHeatingLevel(%d, %q) = %d, want %d", tt.deg, tt.unit, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestHeatingLevelToTemp(t *testing.T) {
+	tests := []struct {
+		level int
+		unit  UnitOfTemperature
+		want  int
+	}{
+		{level: -100, unit: Fahrenheit, want: 55},
+		{level: -58, unit: Fahrenheit, want: 68},
+		{level: -17, unit: Fahrenheit, want: 77},
+		{level: 81, unit: Fahrenheit, want: 107},
+		{level: -58, unit: Celsius, want: 20},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if got := HeatingLevelToTemp(tt.level, tt.unit); got != tt.want {
+				t.Fatalf("HeatingLevelToTemp(%d, %q) = %d, want %d", tt.level, tt.unit, got, tt.want)
+			}
+		}
+	}
+}